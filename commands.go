@@ -0,0 +1,198 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"test-in-go/config"
+	"test-in-go/steps/common"
+	"test-in-go/utils/codegen_helpers"
+	"test-in-go/utils/db_helpers"
+	"test-in-go/utils/report_helpers"
+	"time"
+)
+
+// runCommand dispatches positional subcommands (e.g. "vault set NAME VALUE", "migrate up") and returns the exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "vault":
+		return vaultCommand(args[1:])
+	case "migrate":
+		return migrateCommand(args[1:])
+	case "generate":
+		return generateCommand(args[1:])
+	case "steps":
+		return stepsCommand(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q.\n", args[0])
+		return 2
+	}
+}
+
+// vaultCommand manages the encrypted local secret vault referenced by ${vault:NAME}
+func vaultCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: vault set NAME VALUE | vault list")
+		return 2
+	}
+
+	switch args[0] {
+	case "set":
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "Usage: vault set NAME VALUE")
+			return 2
+		}
+		if err := config.SetVaultSecret(args[1], args[2]); err != nil {
+			logger.Error("Error updating vault: ", err)
+			return 1
+		}
+		fmt.Printf("Stored secret %q in %s\n", args[1], config.VaultPath())
+		return 0
+	case "list":
+		vault, err := config.ReadVault()
+		if err != nil {
+			logger.Error("Error reading vault: ", err)
+			return 1
+		}
+		names := make([]string, 0, len(vault))
+		for name := range vault {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown vault command %q.\n", args[0])
+		return 2
+	}
+}
+
+// migrateCommand applies, reverts or lists the versioned schema migrations of a datasource
+func migrateCommand(args []string) int {
+	usage := "Usage: migrate up|down|status [-datasource NAME] [-steps N]"
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	datasource := flags.String("datasource", db_helpers.DefaultDatasource, "Datasource to migrate")
+	steps := flags.Int("steps", 0, "Number of migrations to apply (up, default all) or revert (down, default 1)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Error("Error loading configuration: ", err)
+		return 1
+	}
+	validateConfig(cfg)
+	connectDatabases(cfg)
+	defer db_helpers.ClosePostgres()
+
+	switch args[0] {
+	case "up":
+		err = db_helpers.MigrateUp(*datasource, *steps)
+	case "down":
+		err = db_helpers.MigrateDown(*datasource, *steps)
+	case "status":
+		var states []db_helpers.MigrationState
+		states, err = db_helpers.MigrationStatus(*datasource)
+		for _, state := range states {
+			applied := "pending"
+			if state.AppliedAt != nil {
+				applied = "applied " + state.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-40s %s\n", state.Version, state.Name, applied)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	if err != nil {
+		logger.Error("Migration failed: ", err)
+		return 1
+	}
+	return 0
+}
+
+// generateCommand writes model structs and builders for a JSON Schema or OpenAPI component
+func generateCommand(args []string) int {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	schema := flags.String("schema", "./contracts/appserver.yaml", "JSON Schema or OpenAPI document")
+	typeName := flags.String("type", "", "OpenAPI component (or JSON Schema definition) to generate, e.g. Order")
+	outDir := flags.String("out", "./utils/data_helpers", "Package directory the files are written to")
+	packageName := flags.String("package", "", "Package name, defaults to the directory name")
+	force := flags.Bool("force", false, "Overwrite files that were not generated")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *typeName == "" {
+		fmt.Fprintln(os.Stderr, "Usage: generate -type NAME [-schema FILE] [-out DIR] [-package NAME] [-force]")
+		return 2
+	}
+
+	written, err := codegen_helpers.Generate(codegen_helpers.GenerateOptions{
+		Schema:  *schema,
+		Type:    *typeName,
+		OutDir:  *outDir,
+		Package: *packageName,
+		Force:   *force,
+	})
+	if err != nil {
+		logger.Error("Generation failed: ", err)
+		return 1
+	}
+	for _, path := range written {
+		fmt.Println("Generated " + path)
+	}
+	return 0
+}
+
+// stepsCommand lists the step definitions with their uses in the features, the unused steps and the undefined ones
+func stepsCommand(args []string) int {
+	usage := "Usage: steps list [-steps DIR] [-features PATH] [-format text|markdown|html] [-out FILE]"
+	if len(args) == 0 || args[0] != "list" {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("steps list", flag.ContinueOnError)
+	stepsDir := flags.String("steps", "./steps", "Directory of the step definitions")
+	features := flags.String("features", featuresDir, "Feature file or directory")
+	format := flags.String("format", "text", "Output format: text, markdown or html")
+	out := flags.String("out", "", "File to write the catalogue to, defaults to the console")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	catalogue, err := common.BuildStepCatalogue(*stepsDir, []string{*features})
+	if err != nil {
+		logger.Error("Error building the step catalogue: ", err)
+		return 1
+	}
+
+	output := os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			logger.Error("Error creating the step catalogue file: ", err)
+			return 1
+		}
+		defer file.Close()
+		output = file
+	}
+	if err := report_helpers.WriteStepCatalogue(output, catalogue, *format); err != nil {
+		logger.Error("Error writing the step catalogue: ", err)
+		return 1
+	}
+	if *out != "" {
+		fmt.Printf("Wrote the step catalogue to %s\n", *out)
+	}
+	return 0
+}
@@ -6,70 +6,138 @@ import (
 
 	// "log"
 	"os"
-	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-// Config structure holds all the configuration from the config.json file
+// Config structure holds all the configuration from the layered config files
 type Config struct {
-	DB  DatabaseConfig `json:"db"`
-	API APIConfig      `json:"api"`
+	DB          DatabaseConfig            `json:"db"`
+	Datasources map[string]DatabaseConfig `json:"datasources"`
+	API         APIConfig                 `json:"api"`
+	Fixtures    FixturesConfig            `json:"fixtures"`
+	Mock        MockConfig                `json:"mock"`
+	Clock       ClockConfig               `json:"clock"`
 }
 
-// DatabaseConfig holds the database-specific configuration
+// DatabaseConfig holds the database-specific configuration.
+// The same structure describes the default database and each named datasource.
 type DatabaseConfig struct {
-	URL                string `json:"url"`
-	MaxOpenConnections int    `json:"maxOpenConnections"`
-	MaxIdleConnections int    `json:"maxIdleConnections"`
+	URL                    string `json:"url" env:"DB_URL"`
+	MaxOpenConnections     int    `json:"maxOpenConnections" env:"DB_MAX_OPEN_CONNECTIONS"`
+	MaxIdleConnections     int    `json:"maxIdleConnections" env:"DB_MAX_IDLE_CONNECTIONS"`
+	ConnMaxLifetimeSeconds int    `json:"connMaxLifetimeSeconds" env:"DB_CONN_MAX_LIFETIME_SECONDS"`
+	ConnMaxIdleTimeSeconds int    `json:"connMaxIdleTimeSeconds" env:"DB_CONN_MAX_IDLE_TIME_SECONDS"`
 }
 
 // APIConfig holds the API-specific configuration
 type APIConfig struct {
-	BaseURL string `json:"base_url"`
-	ApiUser string `json:"api_user"`
-	ApiPass string `json:"api_pass"`
-	Timeout int    `json:"timeout"`
+	BaseURL string `json:"base_url" env:"API_URL"`
+	ApiUser string `json:"api_user" env:"API_USER"`
+	ApiPass string `json:"api_pass" env:"API_PASS" secret:"true"`
+	Timeout int    `json:"timeout" env:"API_TIMEOUT"`
+	// Cassettes controls recording and replaying of the API interactions of each scenario
+	Cassettes CassetteConfig `json:"cassettes"`
+	// Contract validates requests and responses against an OpenAPI document
+	Contract ContractConfig `json:"contract"`
+}
+
+// CassetteConfig selects live, record or replay mode for API requests.
+// Match lists the request parts compared in replay mode: method, path, query, body or header:<Name>.
+// Redact lists the headers whose values are never written to a cassette.
+type CassetteConfig struct {
+	Mode   string   `json:"mode" env:"API_CASSETTE_MODE"`
+	Dir    string   `json:"dir" env:"API_CASSETTE_DIR"`
+	Match  []string `json:"match"`
+	Redact []string `json:"redact"`
+}
+
+// FixturesConfig lists the SQL fixtures applied around the suite and the per-scenario cleanup rules
+type FixturesConfig struct {
+	Suite    []string      `json:"suite"`
+	Teardown []string      `json:"teardown"`
+	Cleanup  []CleanupRule `json:"cleanup"`
+}
+
+// CleanupRule deletes the rows of a table whose key column starts with a prefix after every scenario.
+// The prefix may contain placeholders such as {{testCode}}.
+type CleanupRule struct {
+	Datasource string `json:"datasource"`
+	Table      string `json:"table"`
+	Column     string `json:"column"`
+	Prefix     string `json:"prefix"`
+}
+
+// ContractConfig names the OpenAPI document of the API and what a violation does:
+// off skips validation, warn reports violations and fail also fails the request's step.
+type ContractConfig struct {
+	Spec string `json:"spec" env:"API_CONTRACT_SPEC"`
+	Mode string `json:"mode" env:"API_CONTRACT_MODE"`
+}
+
+// MockConfig holds the settings of the embedded mock API server started with --mock-api
+type MockConfig struct {
+	Port  int    `json:"port" env:"MOCK_API_PORT"`
+	Stubs string `json:"stubs" env:"MOCK_API_STUBS"`
+}
+
+// ClockConfig sets the framework clock that dynamic dates and times are derived from:
+// real uses the real time, fixed freezes it at Date, and offset shifts it by Offset or runs it from Date.
+// Date is 2006-01-02, 2006-01-02T15:04:05 or RFC 3339; Timezone is an IANA name, empty for the local zone.
+type ClockConfig struct {
+	Mode     string `json:"mode" env:"TEST_CLOCK_MODE"`
+	Date     string `json:"date" env:"TEST_DATE"`
+	Offset   string `json:"offset" env:"TEST_CLOCK_OFFSET"`
+	Timezone string `json:"timezone" env:"TEST_TIMEZONE"`
 }
 
 var config *Config
 
-// LoadConfig loads the configuration from config.json or environment variables if they exist
+// LoadConfig loads the configuration for the active environment profile.
+// Layers are applied in order: config.json, config.<env>.json, config.local.json and
+// finally environment variables named by the `env` tag of each field.
+// String values may reference secrets indirectly, see ResolveSecrets.
 func LoadConfig() (*Config, error) {
 	if config != nil {
 		return config, nil // Return if already loaded
 	}
 
-	// Set the default path for config.json
-	configPath := filepath.Join(".", "config", "config.json")
-
-	// Open config.json file
-	file, err := os.Open(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("could not open config.json: %v", err)
+	cfg, problems := LoadPartialConfig()
+	if len(problems) > 0 {
+		return nil, problems[0]
 	}
-	defer file.Close()
+	config = cfg
+	return config, nil
+}
+
+// LoadPartialConfig loads as much of the configuration as it can, for diagnostics such as --check-config.
+// A layer that cannot be read is skipped, a secret reference that cannot be resolved is kept as written and
+// an invalid environment variable is ignored; every such problem is returned with the partial configuration.
+func LoadPartialConfig() (*Config, []error) {
+	// Merge the config files of the active profile
+	merged, problems := loadLayers(Environment())
+
+	// Resolve ${env:...}, ${file:...} and ${vault:...} secret references
+	problems = append(problems, resolveSecretsInMap(merged)...)
 
-	// Decode the JSON file into the config struct
-	config = &Config{}
-	err = json.NewDecoder(file).Decode(config)
+	// Decode the merged JSON into the config struct
+	cfg := &Config{}
+	data, err := json.Marshal(merged)
 	if err != nil {
-		return nil, fmt.Errorf("could not decode config.json: %v", err)
+		problems = append(problems, fmt.Errorf("could not encode merged configuration: %v", err))
+	} else if err := json.Unmarshal(data, cfg); err != nil {
+		problems = append(problems, fmt.Errorf("could not decode merged configuration: %v", err))
 	}
 
 	// Override with environment variables if they exist
-	if dbURL := os.Getenv("DB_URL"); dbURL != "" {
-		config.DB.URL = dbURL
-	}
-	if apiURL := os.Getenv("API_URL"); apiURL != "" {
-		config.API.BaseURL = apiURL
-	}
-	if apiUser := os.Getenv("API_USER"); apiUser != "" {
-		config.API.ApiUser = apiUser
-	}
-	if apiPass := os.Getenv("API_PASS"); apiPass != "" {
-		config.API.ApiPass = apiPass
-	}
+	problems = append(problems, applyEnvOverrides(reflect.ValueOf(cfg).Elem())...)
 
-	return config, nil
+	// Remember secret values so they can be masked wherever they are printed
+	registerConfigSecrets(cfg)
+	return cfg, problems
 }
 
 // GetEnv returns the value from the environment variables or from the config
@@ -81,19 +149,124 @@ func GetEnv(key string) string {
 		return ""
 	}
 
-	// Check for specific keys in the loaded configuration
-	switch key {
-	case "DB_URL":
-		return cfg.DB.URL
-	case "API_URL":
-		return cfg.API.BaseURL
-	case "API_USER":
-		return cfg.API.ApiUser
-	case "API_PASS":
-		return cfg.API.ApiPass
-	default:
-		// If not found in config, return from environment variables or empty string if not set
-		value := os.Getenv(key)
+	// Check for the key among the env names of the loaded configuration
+	if value, ok := Values(cfg)[key]; ok {
 		return value
 	}
+
+	// If not found in config, return from environment variables or empty string if not set
+	return os.Getenv(key)
+}
+
+// Values flattens the configuration into its env names and their effective values
+func Values(cfg *Config) map[string]string {
+	values := make(map[string]string)
+	walkEnvFields(reflect.ValueOf(cfg).Elem(), func(name string, field reflect.Value, structField reflect.StructField) error {
+		values[name] = fmt.Sprint(field.Interface())
+		return nil
+	})
+	return values
+}
+
+// applyEnvOverrides sets every field that has a matching, non-empty environment variable
+// and returns the variables that could not be applied
+func applyEnvOverrides(v reflect.Value) []error {
+	var problems []error
+	walkEnvFields(v, func(name string, field reflect.Value, structField reflect.StructField) error {
+		value := os.Getenv(name)
+		if value == "" {
+			return nil
+		}
+		value, err := ResolveSecrets(value)
+		if err != nil {
+			problems = append(problems, err)
+			return nil
+		}
+		if err := setField(field, value); err != nil {
+			problems = append(problems, fmt.Errorf("invalid value for %s: %v", name, err))
+		}
+		return nil
+	})
+	return problems
+}
+
+// walkEnvFields calls fn for every nested struct field carrying an `env` tag.
+// The fields of named datasources are reached as DATASOURCE_<NAME>_<env>, e.g. DATASOURCE_REPORTING_DB_URL.
+func walkEnvFields(v reflect.Value, fn func(name string, field reflect.Value, structField reflect.StructField) error) error {
+	return walkPrefixedEnvFields(v, "", fn)
+}
+
+// walkPrefixedEnvFields walks the fields of v, prefixing their env names
+func walkPrefixedEnvFields(v reflect.Value, prefix string, fn func(name string, field reflect.Value, structField reflect.StructField) error) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		structField := v.Type().Field(i)
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			if err := walkPrefixedEnvFields(field, prefix, fn); err != nil {
+				return err
+			}
+			continue
+		case field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.Struct:
+			if err := walkEnvMap(field, prefix, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name := structField.Tag.Get("env"); name != "" {
+			if err := fn(prefix+name, field, structField); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkEnvMap walks the struct values of a map such as Datasources in key order.
+// Map values cannot be set in place, so each one is walked as a copy that is stored back.
+func walkEnvMap(m reflect.Value, prefix string, fn func(name string, field reflect.Value, structField reflect.StructField) error) error {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, key := range keys {
+		value := reflect.New(m.Type().Elem()).Elem()
+		value.Set(m.MapIndex(key))
+		if err := walkPrefixedEnvFields(value, prefix+"DATASOURCE_"+envName(key.String())+"_", fn); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, value)
+	}
+	return nil
+}
+
+// envName turns a datasource name into the upper-case form used in env names, e.g. reporting-db becomes REPORTING_DB
+func envName(name string) string {
+	return strings.ToUpper(nonEnvChars.ReplaceAllString(name, "_"))
+}
+
+// nonEnvChars matches the characters that cannot appear in an env name
+var nonEnvChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// setField converts the string value to the kind of the field and assigns it
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Kind())
+	}
+	return nil
 }
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Supported environment profiles, each backed by config/config.<name>.json
+var Profiles = []string{"dev", "sit", "uat", "local-docker"}
+
+// Directory holding the layered config files
+var ConfigDir = filepath.Join(".", "config")
+
+var environment string
+
+// SetEnvironment selects the environment profile used by LoadConfig, overriding ENVIRONMENT.
+// Any previously loaded configuration is discarded so the next LoadConfig call re-reads it.
+func SetEnvironment(name string) {
+	environment = name
+	config = nil
+}
+
+// Environment returns the active profile name from SetEnvironment or the ENVIRONMENT variable.
+// An empty name means only the base config.json (plus local and env overrides) is used.
+func Environment() string {
+	if environment != "" {
+		return environment
+	}
+	return os.Getenv("ENVIRONMENT")
+}
+
+// loadLayers reads and deep-merges the base, profile and local override config files.
+// A layer that cannot be read is left out of the merge and reported.
+func loadLayers(env string) (map[string]interface{}, []error) {
+	var problems []error
+	merged := make(map[string]interface{})
+	addLayer := func(path string) {
+		layer, err := readLayer(path)
+		if err != nil {
+			problems = append(problems, err)
+			return
+		}
+		mergeMaps(merged, layer)
+	}
+
+	addLayer(filepath.Join(ConfigDir, "config.json"))
+
+	if env != "" {
+		if isKnownProfile(env) {
+			addLayer(filepath.Join(ConfigDir, "config."+env+".json"))
+		} else {
+			problems = append(problems, fmt.Errorf("unknown environment profile %q (expected one of %v)", env, Profiles))
+		}
+	}
+
+	// The local override is optional and kept out of version control
+	localPath := filepath.Join(ConfigDir, "config.local.json")
+	if _, err := os.Stat(localPath); err == nil {
+		addLayer(localPath)
+	}
+
+	return merged, problems
+}
+
+// readLayer decodes a single config file into a generic map.
+// Each layer is first decoded strictly against Config so misspelled or unsupported keys are reported.
+func readLayer(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %v", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&Config{}); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %v", path, err)
+	}
+
+	layer := make(map[string]interface{})
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %v", path, err)
+	}
+	return layer, nil
+}
+
+// mergeMaps recursively copies src into dst, with src values taking precedence
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcValue
+	}
+}
+
+func isKnownProfile(env string) bool {
+	for _, profile := range Profiles {
+		if profile == env {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// secretRefPattern matches indirect secret references such as ${env:API_PASS} or ${file:/run/secrets/db}
+var secretRefPattern = regexp.MustCompile(`\$\{(env|file|vault):([^}]+)\}`)
+
+var (
+	secretsMu  sync.RWMutex
+	secrets    = make(map[string]struct{})
+	secretRefs = make(map[string]struct{}) // resolved references, e.g. "env:API_PASS"
+)
+
+// ResolveSecrets replaces every secret reference in value with the value it points to.
+// Resolved values are registered so MaskSecrets hides them from logs, reports and the web UI.
+func ResolveSecrets(value string) (string, error) {
+	var resolveErr error
+	resolved := secretRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		parts := secretRefPattern.FindStringSubmatch(ref)
+		secret, err := resolveSecret(parts[1], parts[2])
+		if err != nil {
+			if resolveErr == nil {
+				resolveErr = err
+			}
+			return ref
+		}
+		RegisterSecret(secret)
+		registerSecretRef(parts[1] + ":" + parts[2])
+		return secret
+	})
+	return resolved, resolveErr
+}
+
+// resolveSecret looks up a single reference from its source
+func resolveSecret(source, name string) (string, error) {
+	switch source {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret ${env:%s}: environment variable is not set", name)
+		}
+		return value, nil
+	case "file":
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("secret ${file:%s}: %v", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case "vault":
+		vault, err := ReadVault()
+		if err != nil {
+			return "", fmt.Errorf("secret ${vault:%s}: %v", name, err)
+		}
+		value, ok := vault[name]
+		if !ok {
+			return "", fmt.Errorf("secret ${vault:%s}: not found in %s", name, VaultPath())
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("unsupported secret source %q", source)
+	}
+}
+
+// resolveSecretsInMap resolves references in every string of a decoded config layer.
+// References that cannot be resolved are kept as written and reported.
+func resolveSecretsInMap(layer map[string]interface{}) []error {
+	keys := make([]string, 0, len(layer))
+	for key := range layer {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []error
+	for _, key := range keys {
+		switch v := layer[key].(type) {
+		case string:
+			resolved, err := ResolveSecrets(v)
+			if err != nil {
+				problems = append(problems, err)
+			}
+			layer[key] = resolved
+		case map[string]interface{}:
+			problems = append(problems, resolveSecretsInMap(v)...)
+		}
+	}
+	return problems
+}
+
+// registerConfigSecrets registers the plaintext secrets of the loaded configuration:
+// fields tagged `secret:"true"` and passwords embedded in URLs, including datasource URLs.
+func registerConfigSecrets(cfg *Config) {
+	walkEnvFields(reflect.ValueOf(cfg).Elem(), func(name string, field reflect.Value, structField reflect.StructField) error {
+		if field.Kind() != reflect.String {
+			return nil
+		}
+		if structField.Tag.Get("secret") == "true" {
+			RegisterSecret(field.String())
+		}
+		registerURLPassword(field.String())
+		return nil
+	})
+}
+
+// registerURLPassword registers the password of a URL's user info, if any
+func registerURLPassword(value string) {
+	if parsed, err := url.Parse(value); err == nil && parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok {
+			RegisterSecret(password)
+		}
+	}
+}
+
+// RegisterSecret marks a value as secret so that MaskSecrets hides it
+func RegisterSecret(value string) {
+	if value == "" || value == secretMask {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secrets[value] = struct{}{}
+}
+
+// registerSecretRef remembers a resolved reference, such as "env:API_PASS", as pointing at a secret
+func registerSecretRef(ref string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secretRefs[ref] = struct{}{}
+}
+
+// IsSecret reports whether value is a registered secret. Values that only contain one, like a longer word, are not.
+func IsSecret(value string) bool {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	_, ok := secrets[value]
+	return ok
+}
+
+// IsSecretReference reports whether a reference such as "env:API_PASS" was resolved as a secret of the configuration
+func IsSecretReference(ref string) bool {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	_, ok := secretRefs[strings.TrimSpace(ref)]
+	return ok
+}
+
+// MaskSecrets replaces every registered secret value in text with a mask.
+// Only whole tokens are masked, so a short password such as "pass" does not hide part of "passport".
+func MaskSecrets(text string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	if len(secrets) == 0 {
+		return text
+	}
+
+	// Replace longer secrets first so overlapping values are fully hidden
+	values := make([]string, 0, len(secrets))
+	for value := range secrets {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	for _, value := range values {
+		text = maskToken(text, value)
+		if escaped := url.QueryEscape(value); escaped != value {
+			text = maskToken(text, escaped)
+		}
+	}
+	return text
+}
+
+// maskToken replaces the occurrences of value that are not part of a longer word or number
+func maskToken(text, value string) string {
+	var b strings.Builder
+	start := 0
+	for {
+		i := strings.Index(text[start:], value)
+		if i < 0 {
+			b.WriteString(text[start:])
+			return b.String()
+		}
+		i += start
+		end := i + len(value)
+		b.WriteString(text[start:i])
+		if isWordByte(text, i-1) || isWordByte(text, end) {
+			b.WriteString(value)
+		} else {
+			b.WriteString(secretMask)
+		}
+		start = end
+	}
+}
+
+// isWordByte reports whether text has a letter or digit at index i
+func isWordByte(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	c := text[i]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validate checks the semantic correctness of the configuration and returns every problem found
+func (c *Config) Validate() []error {
+	var problems []error
+
+	// Database settings
+	problems = append(problems, validateDatabase("db", c.DB)...)
+	for _, name := range DatasourceNames(c) {
+		if name == "default" {
+			problems = append(problems, fmt.Errorf("datasources.default: the name \"default\" is reserved for the db settings, choose another name"))
+			continue
+		}
+		problems = append(problems, validateDatabase("datasources."+name, c.Datasources[name])...)
+	}
+
+	// API settings
+	if err := validateURL(c.API.BaseURL, "http", "https"); err != nil {
+		problems = append(problems, fmt.Errorf("api.base_url: %v", err))
+	}
+	if c.API.Timeout <= 0 {
+		problems = append(problems, fmt.Errorf("api.timeout must be a positive number of seconds, got %d", c.API.Timeout))
+	}
+
+	switch c.API.Cassettes.Mode {
+	case "", "live", "record", "replay":
+	default:
+		problems = append(problems, fmt.Errorf("api.cassettes.mode must be live, record or replay, got %q", c.API.Cassettes.Mode))
+	}
+	if c.API.Cassettes.Mode == "record" || c.API.Cassettes.Mode == "replay" {
+		if c.API.Cassettes.Dir == "" {
+			problems = append(problems, fmt.Errorf("api.cassettes.dir must be set in %s mode", c.API.Cassettes.Mode))
+		}
+	}
+	for _, rule := range c.API.Cassettes.Match {
+		switch {
+		case rule == "method", rule == "path", rule == "query", rule == "body":
+		case strings.HasPrefix(rule, "header:") && len(rule) > len("header:"):
+		default:
+			problems = append(problems, fmt.Errorf("api.cassettes.match: unknown rule %q, expected method, path, query, body or header:<Name>", rule))
+		}
+	}
+
+	switch c.API.Contract.Mode {
+	case "", "off":
+	case "warn", "fail":
+		if c.API.Contract.Spec == "" {
+			problems = append(problems, fmt.Errorf("api.contract.spec must be set in %s mode", c.API.Contract.Mode))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("api.contract.mode must be off, warn or fail, got %q", c.API.Contract.Mode))
+	}
+
+	// Mock API settings
+	if c.Mock.Port < 1 || c.Mock.Port > 65535 {
+		problems = append(problems, fmt.Errorf("mock.port must be between 1 and 65535, got %d", c.Mock.Port))
+	}
+	if c.Mock.Stubs == "" {
+		problems = append(problems, fmt.Errorf("mock.stubs must name the stub directory"))
+	}
+
+	// Clock settings
+	problems = append(problems, validateClock(c.Clock)...)
+
+	// Fixture settings
+	for i, rule := range c.Fixtures.Cleanup {
+		if rule.Table == "" || rule.Column == "" || rule.Prefix == "" {
+			problems = append(problems, fmt.Errorf("fixtures.cleanup[%d] needs a table, a column and a prefix", i))
+		}
+		if rule.Datasource != "" && rule.Datasource != "default" && c.Datasources[rule.Datasource].URL == "" {
+			problems = append(problems, fmt.Errorf("fixtures.cleanup[%d] refers to unknown datasource %q", i, rule.Datasource))
+		}
+	}
+
+	return problems
+}
+
+// validateClock checks the clock mode and that its date, offset and time zone can be read
+func validateClock(clock ClockConfig) []error {
+	var problems []error
+	switch clock.Mode {
+	case "", "real", "offset":
+	case "fixed":
+		if clock.Date == "" {
+			problems = append(problems, fmt.Errorf("clock.date must be set in fixed mode"))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("clock.mode must be real, fixed or offset, got %q", clock.Mode))
+	}
+	if clock.Date != "" {
+		valid := false
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+			if _, err := time.Parse(layout, clock.Date); err == nil {
+				valid = true
+			}
+		}
+		if !valid {
+			problems = append(problems, fmt.Errorf("clock.date must be 2006-01-02, 2006-01-02T15:04:05 or RFC 3339, got %q", clock.Date))
+		}
+	}
+	if clock.Offset != "" {
+		if _, err := time.ParseDuration(clock.Offset); err != nil {
+			problems = append(problems, fmt.Errorf("clock.offset must be a duration such as 72h or -30m, got %q", clock.Offset))
+		}
+	}
+	if clock.Timezone != "" {
+		if _, err := time.LoadLocation(clock.Timezone); err != nil {
+			problems = append(problems, fmt.Errorf("clock.timezone: unknown time zone %q", clock.Timezone))
+		}
+	}
+	return problems
+}
+
+// validateDatabase checks the URL and pool settings of one database, prefixing problems with its config path
+func validateDatabase(prefix string, db DatabaseConfig) []error {
+	var problems []error
+	if err := validateURL(db.URL, "postgres", "postgresql"); err != nil {
+		problems = append(problems, fmt.Errorf("%s.url: %v", prefix, err))
+	}
+	if db.MaxOpenConnections < 0 {
+		problems = append(problems, fmt.Errorf("%s.maxOpenConnections must not be negative, got %d", prefix, db.MaxOpenConnections))
+	}
+	if db.MaxIdleConnections < 0 {
+		problems = append(problems, fmt.Errorf("%s.maxIdleConnections must not be negative, got %d", prefix, db.MaxIdleConnections))
+	}
+	if db.MaxOpenConnections > 0 && db.MaxIdleConnections > db.MaxOpenConnections {
+		problems = append(problems, fmt.Errorf("%s.maxIdleConnections (%d) must not exceed %s.maxOpenConnections (%d)", prefix, db.MaxIdleConnections, prefix, db.MaxOpenConnections))
+	}
+	if db.ConnMaxLifetimeSeconds < 0 {
+		problems = append(problems, fmt.Errorf("%s.connMaxLifetimeSeconds must not be negative, got %d", prefix, db.ConnMaxLifetimeSeconds))
+	}
+	if db.ConnMaxIdleTimeSeconds < 0 {
+		problems = append(problems, fmt.Errorf("%s.connMaxIdleTimeSeconds must not be negative, got %d", prefix, db.ConnMaxIdleTimeSeconds))
+	}
+	return problems
+}
+
+// validateURL ensures the value parses as an absolute URL with one of the allowed schemes and a host
+func validateURL(value string, schemes ...string) error {
+	if value == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %v", MaskURL(value), err)
+	}
+	schemeAllowed := false
+	for _, scheme := range schemes {
+		if strings.EqualFold(parsed.Scheme, scheme) {
+			schemeAllowed = true
+		}
+	}
+	if !schemeAllowed {
+		return fmt.Errorf("URL %q must use one of the schemes %v", MaskURL(value), schemes)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("URL %q has no host", MaskURL(value))
+	}
+	return nil
+}
+
+// MaskedValues returns the flattened configuration with secret values masked, sorted by env name
+func MaskedValues(cfg *Config) []string {
+	var lines []string
+	walkEnvFields(reflect.ValueOf(cfg).Elem(), func(name string, field reflect.Value, structField reflect.StructField) error {
+		value := fmt.Sprint(field.Interface())
+		if structField.Tag.Get("secret") == "true" {
+			value = secretMask
+		} else if field.Kind() == reflect.String {
+			value = MaskSecrets(MaskURL(value))
+		}
+		lines = append(lines, fmt.Sprintf("%s = %s", name, value))
+		return nil
+	})
+	sort.Strings(lines)
+	return lines
+}
+
+// DatasourceNames returns the names of the configured datasources in sorted order
+func DatasourceNames(cfg *Config) []string {
+	names := make([]string, 0, len(cfg.Datasources))
+	for name := range cfg.Datasources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+const secretMask = "******"
+
+// MaskURL hides the password part of a URL's user info
+func MaskURL(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.User == nil {
+		return value
+	}
+	if _, hasPassword := parsed.User.Password(); !hasPassword {
+		return value
+	}
+	parsed.User = url.UserPassword(parsed.User.Username(), secretMask)
+	return strings.Replace(parsed.String(), url.PathEscape(secretMask), secretMask, 1)
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables controlling the local secret vault
+const (
+	VAULT_KEY_ENV  = "TEST_VAULT_KEY"
+	VAULT_FILE_ENV = "TEST_VAULT_FILE"
+)
+
+// VaultPath returns the location of the encrypted vault file
+func VaultPath() string {
+	if path := os.Getenv(VAULT_FILE_ENV); path != "" {
+		return path
+	}
+	return filepath.Join(ConfigDir, "vault.enc")
+}
+
+// ReadVault decrypts the vault file with the key from TEST_VAULT_KEY.
+// A missing vault file is treated as an empty vault.
+func ReadVault() (map[string]string, error) {
+	vault := make(map[string]string)
+
+	data, err := os.ReadFile(VaultPath())
+	if os.IsNotExist(err) {
+		return vault, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read vault: %v", err)
+	}
+
+	gcm, err := vaultCipher()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode vault: %v", err)
+	}
+	if len(raw) < gcm.NonceSize() {
+		return nil, fmt.Errorf("vault file is corrupted")
+	}
+	plaintext, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not unlock vault (wrong %s?): %v", VAULT_KEY_ENV, err)
+	}
+	if err := json.Unmarshal(plaintext, &vault); err != nil {
+		return nil, fmt.Errorf("could not decode vault contents: %v", err)
+	}
+	return vault, nil
+}
+
+// WriteVault encrypts and stores the given secrets in the vault file
+func WriteVault(vault map[string]string) error {
+	gcm, err := vaultCipher()
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(vault)
+	if err != nil {
+		return fmt.Errorf("could not encode vault contents: %v", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("could not generate vault nonce: %v", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	encoded := base64.StdEncoding.EncodeToString(sealed) + "\n"
+	if err := os.WriteFile(VaultPath(), []byte(encoded), 0600); err != nil {
+		return fmt.Errorf("could not write vault: %v", err)
+	}
+	return nil
+}
+
+// SetVaultSecret stores a single secret in the vault, creating the vault if needed
+func SetVaultSecret(name, value string) error {
+	vault, err := ReadVault()
+	if err != nil {
+		return err
+	}
+	vault[name] = value
+	return WriteVault(vault)
+}
+
+// vaultCipher derives the AES-256-GCM cipher from the passphrase in TEST_VAULT_KEY
+func vaultCipher() (cipher.AEAD, error) {
+	passphrase := os.Getenv(VAULT_KEY_ENV)
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s is not set; it is required to unlock the vault", VAULT_KEY_ENV)
+	}
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("could not create vault cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
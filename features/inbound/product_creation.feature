@@ -1,6 +1,67 @@
 Feature: Product Creation
 
+  @snapshot(product.productid=PRD-{{testCode}})
   Scenario: Create a product using dynamic TestCode
     Given a new testcase with ID "110-010-001"
     When a product with the description "Test Product" is created
     Then the product should be created successfully with description "Test Product"
+    And the "product" table should contain:
+      | *productid       | shortdescription | productclass |
+      | PRD-{{testCode}} | Test Product     | CONSUMABLE   |
+    And the "product" table should have 1 inserted, 0 updated and 0 deleted rows
+
+  Scenario: Create a product configured from a table
+    Given a new testcase with ID "110-010-003"
+    And a product with:
+      | field                          | value       |
+      | shortDescription               | Frozen Peas |
+      | ageRestriction                 | 0           |
+      | sku[0].retailPrice.centsValue  | 250         |
+      | sku[0].skuUom[0].weight.scalar | 1000        |
+    When the product is created
+    Then the product should be created successfully with description "Frozen Peas"
+
+  Scenario: Create and read a product with generic HTTP steps
+    Given a new testcase with ID "110-010-007"
+    When I send a "POST" request to "/product" with body:
+      """
+      {
+        "products": [{
+          "productCode": "PRD-${testCode}",
+          "shortDescription": "HTTP Product",
+          "ageRestriction": 0,
+          "productClass": "CONSUMABLE",
+          "barcodes": [{"barcodeType": "EACH", "barcode": "${productBarcode}"}]
+        }]
+      }
+      """
+    Then the response status should be 201
+    And the response header "Content-Type" should contain "json"
+    And the response should contain:
+      | $.id          | <not null>       |
+      | $.productCode | PRD-${testCode}  |
+    And I store "$.productCode" as "productCode"
+    When I send a "GET" request to "/product/${productCode}"
+    Then the response status should be 200
+    And the response field "$.productCode" should be "PRD-${testCode}"
+
+  Scenario Outline: Reject a product with invalid data
+    Given a new testcase with ID "110-010-002"
+    When a product is sent with the "<field>" field <breakage>
+    Then the product should be rejected with status 400
+
+    Examples:
+      | field                    | breakage                |
+      | productCode              | removed                 |
+      | ageRestriction           | of the wrong type       |
+      | shortDescription         | too long                |
+      | barcodes[0].barcodeType  | set to an invalid value |
+
+  @data(products.csv)
+  Scenario Outline: Create products from a data file
+    Given a new testcase with ID "<testcase>"
+    And a product with:
+      | shortDescription | <description>  |
+      | productClass     | <productClass> |
+    When the product is created
+    Then the product should be created successfully with description "<description>"
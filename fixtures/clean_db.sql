@@ -1 +1,3 @@
--- SQL for clean
\ No newline at end of file
+-- SQL for clean
+-- Remove the products created by test runs, keeping the sample data
+DELETE FROM public.product WHERE productid LIKE 'PRD-%' AND productid <> 'PRD-sample123';
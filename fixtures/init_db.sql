@@ -1,10 +1,7 @@
 -- SQL for init
--- Create product table
-CREATE TABLE IF NOT EXISTS product (
-    productid VARCHAR(50) PRIMARY KEY,
-    name VARCHAR(255) NOT NULL
-);
+-- The schema is created by the versioned files in migrations/ (go run . migrate up)
 
 -- Optional: Insert some sample data
 INSERT INTO public.product ("productid", "productlevel", parentid, parentlevel, longdescription, shortdescription, imageurl, "productclass") 
-VALUES ('PRD-sample123', 'PRD', 'LOREWI', 'PH1', 'Go sample, consectetur adipiscing elit. Suspendisse poten.', 'desc13279101', 'ttp://www.example.com/stracciatella.png', 'CONSUMABLE');
\ No newline at end of file
+VALUES ('PRD-sample123', 'PRD', 'LOREWI', 'PH1', 'Go sample, consectetur adipiscing elit. Suspendisse poten.', 'desc13279101', 'ttp://www.example.com/stracciatella.png', 'CONSUMABLE')
+ON CONFLICT (productid) DO NOTHING;
@@ -3,13 +3,19 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"test-in-go/config"
+	"test-in-go/mockserver"
+	"test-in-go/steps/common"
 	"test-in-go/steps/inbound"
+	"test-in-go/utils/data_helpers"
 	"test-in-go/utils/db_helpers"
 	"test-in-go/utils/logging_helpers"
+	"test-in-go/utils/protocol_helpers"
 	"test-in-go/utils/report_helpers"
 	"test-in-go/webui"
+	"time"
 
 	"github.com/cucumber/godog"
 	"github.com/sirupsen/logrus"
@@ -17,13 +23,40 @@ import (
 
 var logger *logrus.Logger
 
+// featuresDir holds every feature file; test IDs are checked across all of them, whichever subset runs
+const featuresDir = "./features"
+
 func main() {
 	runTestsFlag := flag.Bool("run-tests", false, "Run tests only")
 	webUIFlag := flag.Bool("web-ui", false, "Launch web UI")
+	envFlag := flag.String("env", "", "Environment profile to load (dev, sit, uat, local-docker); defaults to ENVIRONMENT")
+	checkConfigFlag := flag.Bool("check-config", false, "Print the effective configuration and its problems, then exit")
+	mockAPIFlag := flag.Bool("mock-api", false, "Serve the API from the stubs in mock.stubs; with --run-tests the tests run against it")
+	testDateFlag := flag.String("test-date", "", "Run as if today were this date (2006-01-02) or time (RFC 3339); overrides clock.date")
+	seedFlag := flag.Int64("seed", 0, "Seed of the random test data, to reproduce an earlier run; 0 picks a new seed")
 	flag.Parse()
 
 	// Set up logger
-	logger = logging_helpers.SetupLogger()
+	logger = logging_helpers.Logger()
+
+	// Select the environment profile before the configuration is loaded
+	if *envFlag != "" {
+		config.SetEnvironment(*envFlag)
+	}
+
+	// Reuse the seed of an earlier run so that it generates the same data
+	if *seedFlag != 0 {
+		data_helpers.SetSeed(*seedFlag)
+	}
+
+	// Run a subcommand when one is given
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args()))
+	}
+
+	if *checkConfigFlag {
+		os.Exit(checkConfig())
+	}
 
 	// Set up the database connection
 	cfg, err := config.LoadConfig()
@@ -31,18 +64,38 @@ func main() {
 		logger.Fatal("Error loading configuration: ", err)
 		os.Exit(1)
 	}
+	if *testDateFlag != "" {
+		cfg.Clock.Date = *testDateFlag
+		if cfg.Clock.Mode == "" || cfg.Clock.Mode == data_helpers.ClockReal {
+			cfg.Clock.Mode = data_helpers.ClockOffset
+		}
+	}
+	validateConfig(cfg)
 
-	// Connect to the database using the configuration
-	err = db_helpers.ConnectPostgres(cfg.DB.URL)
-	if err != nil {
-		logger.Fatal("Failed to connect to the database "+config.GetEnv("DB_URL")+" | "+cfg.DB.URL+" due to:", err)
+	// Set the clock that dynamic dates and times are derived from
+	if err := data_helpers.ConfigureClock(cfg.Clock.Mode, cfg.Clock.Date, cfg.Clock.Offset, cfg.Clock.Timezone); err != nil {
+		logger.Fatal("Error configuring the clock: ", err)
 		os.Exit(1)
 	}
+
+	// Serve the mock API alone; it needs no environment, the database is only used by stubs that write rows
+	if *mockAPIFlag && !*runTestsFlag && !*webUIFlag {
+		startMockAPI(cfg, true)
+		return
+	}
+
+	// Connect to the default database and the named datasources using the configuration
+	connectDatabases(cfg)
 	defer db_helpers.ClosePostgres()
 
+	// Start the mock API in the background of the test run
+	if *mockAPIFlag {
+		startMockAPI(cfg, false)
+	}
+
 	// Decide whether to run the web server or the tests
 	if *runTestsFlag {
-		runTestsOnly()
+		runTestsOnly(allocatesTestRound(cfg, *mockAPIFlag))
 	} else if *webUIFlag {
 		webui.StartWebServer()
 	} else {
@@ -50,7 +103,145 @@ func main() {
 	}
 }
 
-func runTestsOnly() {
+// validateConfig stops the run before anything touches the environment when the configuration is invalid
+func validateConfig(cfg *config.Config) {
+	if problems := cfg.Validate(); len(problems) > 0 {
+		for _, problem := range problems {
+			logger.Error("Invalid configuration: ", problem)
+		}
+		logger.Fatal("Configuration has ", len(problems), " problem(s); run with --check-config for details.")
+		os.Exit(1)
+	}
+}
+
+// connectDatabases connects the default database and every named datasource, stopping the run on failure
+func connectDatabases(cfg *config.Config) {
+	if err := openDatabases(cfg); err != nil {
+		logger.Fatal(err)
+		os.Exit(1)
+	}
+}
+
+// openDatabases connects the default database and every named datasource
+func openDatabases(cfg *config.Config) error {
+	err := db_helpers.ConnectPostgres(cfg.DB.URL, poolSettings(cfg.DB))
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database %s due to: %v", config.MaskURL(cfg.DB.URL), err)
+	}
+
+	for _, name := range config.DatasourceNames(cfg) {
+		ds := cfg.Datasources[name]
+		if err := db_helpers.ConnectDatasource(name, ds.URL, poolSettings(ds)); err != nil {
+			return fmt.Errorf("failed to connect to the database %s due to: %v", config.MaskURL(ds.URL), err)
+		}
+	}
+	return nil
+}
+
+// poolSettings converts the configured pool limits into db_helpers settings
+func poolSettings(db config.DatabaseConfig) db_helpers.PoolSettings {
+	return db_helpers.PoolSettings{
+		MaxOpenConnections: db.MaxOpenConnections,
+		MaxIdleConnections: db.MaxIdleConnections,
+		ConnMaxLifetime:    time.Duration(db.ConnMaxLifetimeSeconds) * time.Second,
+		ConnMaxIdleTime:    time.Duration(db.ConnMaxIdleTimeSeconds) * time.Second,
+	}
+}
+
+// startMockAPI serves the stubs on the configured mock port. In the foreground it blocks until the process stops;
+// in the background the API base URL is pointed at the mock so the tests need no application server.
+func startMockAPI(cfg *config.Config, foreground bool) {
+	stubs, err := mockserver.LoadStubs(cfg.Mock.Stubs)
+	if err != nil {
+		logger.Fatal("Error loading mock API stubs: ", err)
+		os.Exit(1)
+	}
+	baseURL, err := url.Parse(cfg.API.BaseURL)
+	if err != nil {
+		logger.Fatal("Invalid API base URL: ", err)
+		os.Exit(1)
+	}
+	server, err := mockserver.NewServer(stubs, baseURL.Path, logger)
+	if err != nil {
+		logger.Fatal("Error preparing the mock API: ", err)
+		os.Exit(1)
+	}
+
+	if foreground {
+		if mockserver.WritesToDatabase(stubs) {
+			if err := openDatabases(cfg); err != nil {
+				logger.Warn("Mock API serves without a database, the db writes of the stubs are skipped: ", err)
+				server.SkipDatabaseWrites()
+			} else {
+				defer db_helpers.ClosePostgres()
+			}
+		}
+		logger.Infof("Mock API serving %d stub(s) on port %d", len(stubs), cfg.Mock.Port)
+		if err := server.ListenAndServe(cfg.Mock.Port); err != nil {
+			logger.Fatal("Mock API stopped: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	mockURL, err := server.Start(cfg.Mock.Port)
+	if err != nil {
+		logger.Fatal("Error starting the mock API: ", err)
+		os.Exit(1)
+	}
+	logger.Infof("Mock API serving %d stub(s) at %s", len(stubs), mockURL)
+	cfg.API.BaseURL = mockURL
+}
+
+// checkConfig prints the effective, secret-masked configuration and its problems, returning the exit code
+func checkConfig() int {
+	environment := config.Environment()
+	if environment == "" {
+		environment = "base"
+	}
+
+	// Show what could be loaded even when some layers, secrets or variables fail
+	cfg, problems := config.LoadPartialConfig()
+	if len(problems) > 0 {
+		fmt.Printf("Effective configuration (environment: %s, partial because of the problems below):\n", environment)
+	} else {
+		fmt.Printf("Effective configuration (environment: %s):\n", environment)
+	}
+	for _, line := range config.MaskedValues(cfg) {
+		fmt.Println("  " + line)
+	}
+	problems = append(problems, cfg.Validate()...)
+
+	if len(problems) == 0 {
+		fmt.Println("No configuration problems found.")
+		return 0
+	}
+	fmt.Printf("Found %d configuration problem(s):\n", len(problems))
+	for _, problem := range problems {
+		fmt.Println("  - " + problem.Error())
+	}
+	return 1
+}
+
+// allocatesTestRound reports whether a test run takes a new round of the day. Runs that create no data in a shared
+// environment, against the mock API or replaying cassettes, and runs on a fixed clock, which repeat the same day, keep round 1.
+func allocatesTestRound(cfg *config.Config, mockAPI bool) bool {
+	return !mockAPI && cfg.API.Cassettes.Mode != protocol_helpers.CassetteReplay && cfg.Clock.Mode != data_helpers.ClockFixed
+}
+
+func runTestsOnly(allocateRound bool) {
+	// Give this run its own round of the day, so IDs derived from it differ from earlier runs
+	if allocateRound {
+		round, err := data_helpers.AllocateTestRound()
+		if err != nil {
+			logger.Fatal("Error allocating the test round: ", err)
+			os.Exit(1)
+		}
+		logger.Infof("Test round %d of %s", round, data_helpers.TestDate())
+	} else {
+		logger.Infof("Test round %d of %s, not allocated for offline or fixed-clock runs", data_helpers.TestRound, data_helpers.TestDate())
+	}
+
 	// Initialize the pretty report
 	err := report_helpers.InitPrettyReport()
 	if err != nil {
@@ -84,6 +275,24 @@ func runGodogTests() int {
 		Strict: true,
 	}
 
+	// Generate the Examples of @data(file) scenarios from their data files
+	features, paths, err := common.ExpandDataExamples(opts.Paths)
+	if err != nil {
+		logger.Error("Error expanding data-driven examples: ", err)
+		return 1
+	}
+	opts.FeatureContents, opts.Paths = features, paths
+
+	// Stop before anything runs when test IDs break the naming convention or collide with any other feature file
+	allFeatures, allPaths, err := common.ExpandDataExamples([]string{featuresDir})
+	if err == nil {
+		err = common.CheckTestIDs(allFeatures, allPaths)
+	}
+	if err != nil {
+		logger.Error("Error checking test IDs: ", err)
+		return 1
+	}
+
 	// Run the test suite and return the status
 	return godog.TestSuite{
 		Name:                 "Product Creation Test",
@@ -96,8 +305,19 @@ func runGodogTests() int {
 // InitializeTestSuite - this can be used to prepare data, etc.
 func InitializeTestSuite(ctx *godog.TestSuiteContext) {
 	// Any necessary suite-level setup goes here
+	common.InitializeFixtureSuite(ctx)
 }
 
 func InitializeScenario(ctx *godog.ScenarioContext) {
+	common.InitializeSeedHooks(ctx)
+	common.InitializeExpressionHooks(ctx)
 	inbound.InitializeProductSteps(ctx)
+	common.InitializeHTTPSteps(ctx)
+	common.InitializeDatabaseSteps(ctx)
+	common.InitializeTransactionHooks(ctx)
+	common.InitializeFixtureHooks(ctx)
+	common.InitializeSnapshotSteps(ctx)
+	common.InitializeCassetteHooks(ctx)
+	common.InitializeFaultSteps(ctx)
+	common.InitializeClockSteps(ctx)
 }
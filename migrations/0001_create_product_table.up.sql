@@ -0,0 +1,11 @@
+-- Create the product table used by the product creation scenarios
+CREATE TABLE IF NOT EXISTS product (
+    productid VARCHAR(50) PRIMARY KEY,
+    productlevel VARCHAR(10),
+    parentid VARCHAR(50),
+    parentlevel VARCHAR(10),
+    longdescription VARCHAR(1000),
+    shortdescription VARCHAR(255),
+    imageurl VARCHAR(500),
+    productclass VARCHAR(50)
+);
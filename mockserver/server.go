@@ -0,0 +1,305 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"test-in-go/utils/data_helpers"
+	"test-in-go/utils/db_helpers"
+	validationhelpers "test-in-go/utils/validation_helpers"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Server answers requests from stubs, in place of the application server
+type Server struct {
+	stubs     []Stub
+	templates []*template.Template
+	basePath  string
+	logger    *logrus.Logger
+	skipDB    bool
+}
+
+// RequestData is the data available to response templates
+type RequestData struct {
+	Method  string
+	Path    string
+	Params  map[string]string
+	Query   map[string]string
+	Headers map[string]string
+	RawBody string
+	Body    interface{}
+}
+
+// NewServer prepares a server for the stubs. Requests are matched on their path below basePath,
+// which is the path of the configured API base URL (e.g. /api).
+func NewServer(stubs []Stub, basePath string, logger *logrus.Logger) (*Server, error) {
+	server := &Server{
+		stubs:    stubs,
+		basePath: strings.TrimSuffix(basePath, "/"),
+		logger:   logger,
+	}
+	for _, stub := range stubs {
+		tmpl, err := template.New(stub.label()).Funcs(templateFuncs).Parse(stub.Response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("stub %s: invalid response body template: %v", stub.label(), err)
+		}
+		server.templates = append(server.templates, tmpl)
+	}
+	return server, nil
+}
+
+// SkipDatabaseWrites serves the stubs without their db side effects, for a mock running without a database
+func (s *Server) SkipDatabaseWrites() {
+	s.skipDB = true
+}
+
+// Start listens on the port in the background and returns the address the server is reachable on
+func (s *Server) Start(port int) (string, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return "", fmt.Errorf("could not listen on port %d: %v", port, err)
+	}
+	go http.Serve(listener, s)
+	return fmt.Sprintf("http://localhost:%d%s", port, s.basePath), nil
+}
+
+// ListenAndServe serves requests on the port until the process stops
+func (s *Server) ListenAndServe(port int) error {
+	return http.ListenAndServe(fmt.Sprintf(":%d", port), s)
+}
+
+// ServeHTTP answers a request with the first matching stub, or 404 when no stub matches
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	request := newRequestData(r, strings.TrimPrefix(r.URL.Path, s.basePath), body)
+
+	for i := range s.stubs {
+		stub := &s.stubs[i]
+		params, ok := stub.matches(request)
+		if !ok {
+			continue
+		}
+		request.Params = params
+		s.respond(w, stub, s.templates[i], request)
+		return
+	}
+
+	s.logger.Warnf("Mock API: no stub matches %s %s", request.Method, request.Path)
+	http.Error(w, fmt.Sprintf("no stub matches %s %s", request.Method, request.Path), http.StatusNotFound)
+}
+
+// respond writes the stub's side effects and rendered response
+func (s *Server) respond(w http.ResponseWriter, stub *Stub, tmpl *template.Template, request *RequestData) {
+	if stub.Response.DelayMs > 0 {
+		time.Sleep(time.Duration(stub.Response.DelayMs) * time.Millisecond)
+	}
+
+	for _, write := range stub.DB {
+		if s.skipDB {
+			s.logger.Infof("Mock API: stub %s skipped its write to the %s table, no database is connected", stub.label(), write.Table)
+			continue
+		}
+		if err := write.apply(request.Body); err != nil {
+			s.logger.Errorf("Mock API: stub %s could not write to the database: %v", stub.label(), err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, request); err != nil {
+		s.logger.Errorf("Mock API: stub %s could not render its response: %v", stub.label(), err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for name, value := range stub.Response.Headers {
+		w.Header().Set(name, value)
+	}
+	if w.Header().Get("Content-Type") == "" && rendered.Len() > 0 {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(stub.Response.Status)
+	w.Write(rendered.Bytes())
+	s.logger.Infof("Mock API: %s %s -> %d (%s)", request.Method, request.Path, stub.Response.Status, stub.label())
+}
+
+// newRequestData collects the parts of a request that stubs match on and templates use
+func newRequestData(r *http.Request, path string, body []byte) *RequestData {
+	request := &RequestData{
+		Method:  r.Method,
+		Path:    path,
+		Query:   make(map[string]string),
+		Headers: make(map[string]string),
+		RawBody: string(body),
+	}
+	for name := range r.URL.Query() {
+		request.Query[name] = r.URL.Query().Get(name)
+	}
+	for name := range r.Header {
+		request.Headers[name] = r.Header.Get(name)
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if document, err := validationhelpers.ParseJSON(body); err == nil {
+			request.Body = document
+		}
+	}
+	return request
+}
+
+// matches reports whether the request meets every condition of the stub and returns the path parameters
+func (s *Stub) matches(request *RequestData) (map[string]string, bool) {
+	if s.Request.Method != "" && !strings.EqualFold(s.Request.Method, request.Method) {
+		return nil, false
+	}
+	params, ok := matchPath(s.Request.Path, request.Path)
+	if !ok {
+		return nil, false
+	}
+
+	for name, expectation := range s.Request.Headers {
+		var actual interface{}
+		if value, exists := request.Headers[http.CanonicalHeaderKey(name)]; exists {
+			actual = value
+		}
+		if matched, err := db_helpers.MatchValue(expectation, actual); err != nil || !matched {
+			return nil, false
+		}
+	}
+
+	for path, expectation := range s.Request.Body {
+		if request.Body == nil {
+			return nil, false
+		}
+		actual, err := validationhelpers.GetJSONPathValue(request.Body, path)
+		if err != nil {
+			if expectation == db_helpers.NullValue {
+				continue
+			}
+			return nil, false
+		}
+		if matched, err := db_helpers.MatchValue(expectation, jsonValue(actual)); err != nil || !matched {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// matchPath compares a path pattern such as /product/{id} with a request path segment by segment
+func matchPath(pattern, path string) (map[string]string, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// apply inserts the rows described by the write for a request body
+func (w StubDBWrite) apply(body interface{}) error {
+	if body == nil {
+		return fmt.Errorf("table %s: the request has no JSON body", w.Table)
+	}
+	datasource := w.Datasource
+	if datasource == "" {
+		datasource = db_helpers.DefaultDatasource
+	}
+
+	elements := []interface{}{body}
+	if w.ForEach != "" {
+		value, err := validationhelpers.GetJSONPathValue(body, w.ForEach)
+		if err != nil {
+			return err
+		}
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("forEach %s is not an array", w.ForEach)
+		}
+		elements = array
+	}
+
+	for _, element := range elements {
+		values := make(map[string]interface{}, len(w.Columns))
+		for column, source := range w.Columns {
+			if !strings.HasPrefix(source, "$") {
+				values[column] = source
+				continue
+			}
+			// Optional fields missing from the body are stored as NULL, as the API does
+			value, err := validationhelpers.GetJSONPathValue(element, source)
+			if errors.Is(err, validationhelpers.ErrJSONPathNotFound) {
+				values[column] = nil
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			values[column] = jsonValue(value)
+		}
+		if err := db_helpers.InsertRow(datasource, w.Table, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonValue turns objects and arrays into their JSON text so they can be compared and stored like scalars
+func jsonValue(value interface{}) interface{} {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return validationhelpers.FormatJSONValue(value)
+	}
+	return value
+}
+
+// templateFuncs are available in response body templates
+var templateFuncs = template.FuncMap{
+	// jsonPath reads a value from a decoded JSON document, e.g. {{ jsonPath .Body "$.products[0].productCode" }}
+	"jsonPath": func(document interface{}, path string) (string, error) {
+		value, err := validationhelpers.GetJSONPathValue(document, path)
+		if err != nil {
+			return "", err
+		}
+		return validationhelpers.FormatJSONValue(value), nil
+	},
+	// json renders a value as JSON, e.g. {{ json (index .Body "products") }}
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	},
+	// uuid returns a UUID from the seeded generator, so mock responses are reproduced with --seed
+	"uuid": data_helpers.RandomUUID,
+	// now formats the current time of the framework clock with a Go layout, RFC 3339 by default
+	"now": func(layout ...string) string {
+		if len(layout) > 0 {
+			return data_helpers.Now().Format(layout[0])
+		}
+		return data_helpers.Now().Format(time.RFC3339)
+	},
+}
@@ -0,0 +1,30 @@
+package mockserver
+
+import (
+	"bytes"
+	"test-in-go/utils/data_helpers"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateUUIDIsSeeded(t *testing.T) {
+	tmpl := template.Must(template.New("body").Funcs(templateFuncs).Parse(`{"id": "{{ uuid }}"}`))
+	render := func() string {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, nil); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	data_helpers.SeedScenario("mock uuid")
+	first := render()
+	data_helpers.SeedScenario("mock uuid")
+	if second := render(); second != first {
+		t.Errorf("uuid with the same seed gave %s and %s", first, second)
+	}
+	data_helpers.SeedScenario("another scenario")
+	if other := render(); other == first {
+		t.Errorf("uuid of another scenario repeated %s", first)
+	}
+}
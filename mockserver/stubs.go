@@ -0,0 +1,143 @@
+package mockserver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Stub describes one mocked route: the request it matches, the response it returns
+// and, optionally, the rows it writes to the database.
+type Stub struct {
+	Name     string        `json:"name" yaml:"name"`
+	Request  StubRequest   `json:"request" yaml:"request"`
+	Response StubResponse  `json:"response" yaml:"response"`
+	DB       []StubDBWrite `json:"db" yaml:"db"`
+	source   string
+}
+
+// StubRequest lists the conditions a request must meet.
+// Path segments written as {name} match any value and are available to templates as .Params.name.
+// Header and body values use the same expectations as table assertions, e.g. "contains json" or "<not null>";
+// body keys are JSONPaths such as $.products[0].productCode.
+type StubRequest struct {
+	Method  string            `json:"method" yaml:"method"`
+	Path    string            `json:"path" yaml:"path"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	Body    map[string]string `json:"body" yaml:"body"`
+}
+
+// StubResponse is returned for a matching request. Body and header values are Go templates.
+type StubResponse struct {
+	Status  int               `json:"status" yaml:"status"`
+	DelayMs int               `json:"delayMs" yaml:"delayMs"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	Body    string            `json:"body" yaml:"body"`
+}
+
+// StubDBWrite inserts one row into a table for the request, or one row per element of the ForEach array.
+// Column values starting with $ are JSONPaths into the request body (or the array element), others are literals;
+// a JSONPath whose field is missing from the body stores NULL.
+type StubDBWrite struct {
+	Datasource string            `json:"datasource" yaml:"datasource"`
+	Table      string            `json:"table" yaml:"table"`
+	ForEach    string            `json:"forEach" yaml:"forEach"`
+	Columns    map[string]string `json:"columns" yaml:"columns"`
+}
+
+// stubFile is the layout of a stub file: a list of stubs under the "stubs" key
+type stubFile struct {
+	Stubs []Stub `json:"stubs" yaml:"stubs"`
+}
+
+// WritesToDatabase reports whether any stub inserts rows, so the mock needs a database connection
+func WritesToDatabase(stubs []Stub) bool {
+	for _, stub := range stubs {
+		if len(stub.DB) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadStubs reads every .json, .yaml and .yml file of a directory in name order.
+// Stubs are matched in the order they are loaded, so more specific stubs should come first.
+func LoadStubs(dir string) ([]Stub, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read stub directory %s: %v", dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var stubs []Stub
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read stub file %s: %v", path, err)
+		}
+
+		// JSON is valid YAML, so one decoder serves both formats
+		var file stubFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("invalid stub file %s: %v", path, err)
+		}
+		for i, stub := range file.Stubs {
+			if err := stub.validate(); err != nil {
+				return nil, fmt.Errorf("%s: stub %d: %v", path, i+1, err)
+			}
+			stub.source = name
+			stubs = append(stubs, stub)
+		}
+	}
+	return stubs, nil
+}
+
+// validate checks the parts of a stub that would otherwise only fail when a request arrives
+func (s *Stub) validate() error {
+	if s.Request.Path == "" || !strings.HasPrefix(s.Request.Path, "/") {
+		return fmt.Errorf("request.path must start with /, got %q", s.Request.Path)
+	}
+	for path := range s.Request.Body {
+		if !strings.HasPrefix(path, "$") {
+			return fmt.Errorf("request.body key %q must be a JSONPath starting with $", path)
+		}
+	}
+	if s.Response.Status == 0 {
+		s.Response.Status = 200
+	}
+	if s.Response.Status < 100 || s.Response.Status > 599 {
+		return fmt.Errorf("response.status %d is not a valid HTTP status", s.Response.Status)
+	}
+	if s.Response.DelayMs < 0 {
+		return fmt.Errorf("response.delayMs must not be negative, got %d", s.Response.DelayMs)
+	}
+	for i, write := range s.DB {
+		if write.Table == "" || len(write.Columns) == 0 {
+			return fmt.Errorf("db[%d] needs a table and at least one column", i)
+		}
+	}
+	return nil
+}
+
+// label names the stub in logs
+func (s *Stub) label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("%s %s (%s)", s.Request.Method, s.Request.Path, s.source)
+}
@@ -2,8 +2,8 @@
 
 echo "Preparing the database..."
 
-# Set up the database using the init script
-psql -h localhost -U user -d test_db -f ./fixtures/init_db.sql
+# Create or update the schema using the versioned migrations, then load the sample data
+go run . migrate up && psql -h localhost -U user -d test_db -f ./fixtures/init_db.sql
 
 # Check for errors
 if [ $? -ne 0 ]; then
@@ -0,0 +1,20 @@
+package common
+
+import (
+	"context"
+	"test-in-go/utils/protocol_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// InitializeCassetteHooks inserts the cassette of each scenario before it runs and ejects it afterwards,
+// so API requests are recorded or replayed per scenario when api.cassettes.mode is record or replay.
+func InitializeCassetteHooks(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		return ctx, protocol_helpers.InsertCassette(sc.Uri, sc.Name)
+	})
+
+	ctx.After(func(ctx context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		return ctx, protocol_helpers.EjectCassette()
+	})
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"test-in-go/utils/data_helpers"
+	"test-in-go/utils/report_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// theTestDateIs moves the framework clock to a date such as "2026-12-31", or a date and time, for this scenario
+func theTestDateIs(date string) error {
+	stepName := "Set test date"
+	if err := data_helpers.SetTestDate(date); err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
+		return err
+	}
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", fmt.Sprintf("Clock: %s", data_helpers.ClockDescription()))
+	return nil
+}
+
+// theTestTimeZoneIs switches the framework clock to a time zone such as "Europe/London" for this scenario
+func theTestTimeZoneIs(timezone string) error {
+	stepName := "Set test time zone"
+	if err := data_helpers.SetClockTimezone(timezone); err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
+		return err
+	}
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", fmt.Sprintf("Clock: %s", data_helpers.ClockDescription()))
+	return nil
+}
+
+// InitializeClockSteps registers the clock steps and restores the configured clock after every scenario.
+func InitializeClockSteps(ctx *godog.ScenarioContext) {
+	ctx.Step(`^the test date is "([^"]*)"$`, theTestDateIs)
+	ctx.Step(`^the test time zone is "([^"]*)"$`, theTestTimeZoneIs)
+
+	ctx.After(func(ctx context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		data_helpers.ResetClock()
+		return ctx, nil
+	})
+}
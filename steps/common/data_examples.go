@@ -0,0 +1,220 @@
+package common
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"test-in-go/utils/data_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// dataTag is the tag of a Scenario Outline whose examples come from a data file, e.g. @data(products.csv)
+const dataTag = "data"
+
+// Keywords that start a scenario, which may carry @data tags, and the keywords that end the scenario before them
+var (
+	scenarioKeywords = []string{"Scenario Outline:", "Scenario Template:", "Scenario:", "Example:"}
+	sectionKeywords  = []string{"Feature:", "Background:", "Rule:"}
+)
+
+// ExpandDataExamples prepares the feature files under the given paths for godog. Features using @data(file) tags
+// are returned as contents with one generated Examples table per data file; the other features are returned as paths.
+func ExpandDataExamples(paths []string) ([]godog.Feature, []string, error) {
+	return expandDataExamples(paths, true)
+}
+
+// expandDataExamples generates the Examples of @data(file) scenarios. Without resolveRows the cells are kept
+// as written in the data files, placeholders included, which is how the step catalogue shows them.
+func expandDataExamples(paths []string, resolveRows bool) ([]godog.Feature, []string, error) {
+	files, err := featureFiles(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var contents []godog.Feature
+	var remaining []string
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read feature %s: %v", file, err)
+		}
+		if !strings.Contains(string(source), "@"+dataTag+"(") {
+			remaining = append(remaining, file)
+			continue
+		}
+		expanded, err := expandFeature(string(source), resolveRows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("feature %s: %v", file, err)
+		}
+		contents = append(contents, godog.Feature{Name: file, Contents: []byte(expanded)})
+	}
+	return contents, remaining, nil
+}
+
+// featureFiles lists the .feature files of files and directories, in path order
+func featureFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("feature path %q is not available: %v", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		var found []string
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && strings.HasSuffix(file, ".feature") {
+				found = append(found, file)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// expandFeature appends an Examples table to every scenario tagged with @data(file).
+// The table is placed after the last step or example of the scenario, before the tags and blank lines of the next one.
+func expandFeature(source string, resolveRows bool) (string, error) {
+	var (
+		out         []string
+		pendingData []string // @data files of the tag lines read since the last keyword
+		activeData  []string // @data files of the current scenario
+		activeLine  string   // keyword line of the current scenario, for its indentation
+		holdStart   = -1     // start of the trailing tag, comment and blank lines in out
+		docString   string   // delimiter of the doc string being read, if any
+	)
+
+	closeScenario := func() error {
+		if len(activeData) == 0 {
+			return nil
+		}
+		at := len(out)
+		if holdStart >= 0 {
+			at = holdStart
+		}
+		examples, err := dataExamples(activeData, leadingSpace(activeLine)+"  ", strings.TrimSpace(activeLine), resolveRows)
+		if err != nil {
+			return err
+		}
+		out = append(out[:at], append(examples, out[at:]...)...)
+		activeData = nil
+		return nil
+	}
+
+	for _, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case docString != "":
+			if strings.HasPrefix(trimmed, docString) {
+				docString = ""
+			}
+		case strings.HasPrefix(trimmed, `"""`) || strings.HasPrefix(trimmed, "```"):
+			docString = trimmed[:3]
+		case strings.HasPrefix(trimmed, "@"):
+			if holdStart < 0 {
+				holdStart = len(out)
+			}
+			for _, tag := range strings.Fields(trimmed) {
+				if match := tagPattern.FindStringSubmatch(tag); match != nil && match[1] == dataTag {
+					pendingData = append(pendingData, match[2])
+				}
+			}
+			out = append(out, line)
+			continue
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			if holdStart < 0 {
+				holdStart = len(out)
+			}
+			out = append(out, line)
+			continue
+		case hasKeyword(trimmed, []string{"Examples:", "Scenarios:"}):
+			if len(pendingData) > 0 {
+				return "", fmt.Errorf("@%s(%s) must tag the scenario, not its Examples", dataTag, pendingData[0])
+			}
+		case hasKeyword(trimmed, scenarioKeywords) || hasKeyword(trimmed, sectionKeywords):
+			if err := closeScenario(); err != nil {
+				return "", err
+			}
+			if hasKeyword(trimmed, scenarioKeywords) {
+				activeData, activeLine = pendingData, line
+			}
+			pendingData = nil
+		}
+
+		out = append(out, line)
+		holdStart = -1
+	}
+
+	if err := closeScenario(); err != nil {
+		return "", err
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// dataExamples renders one Examples table per data file of a scenario, with placeholders resolved per row when resolveRows is set.
+// The generator is seeded from the run seed, the scenario, the file and the row before each row, so the values
+// are the same whenever the features are loaded with the same seed, and --seed reproduces them.
+func dataExamples(files []string, indent, scenario string, resolveRows bool) ([]string, error) {
+	var lines []string
+	for _, file := range files {
+		table, err := data_helpers.LoadDataFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if len(table.Columns) == 0 || len(table.Rows) == 0 {
+			return nil, fmt.Errorf("data file %q has no examples", file)
+		}
+
+		lines = append(lines, "", indent+"Examples: "+file, indent+"  "+tableRow(table.Columns))
+		for n, row := range table.Rows {
+			if !resolveRows {
+				lines = append(lines, indent+"  "+tableRow(row))
+				continue
+			}
+			data_helpers.SeedScenario(fmt.Sprintf("%s\n%s\n%d", scenario, file, n))
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = data_helpers.ResolveRowPlaceholders(cell)
+			}
+			lines = append(lines, indent+"  "+tableRow(cells))
+		}
+	}
+	return lines, nil
+}
+
+// tableRow renders cells as a Gherkin table row, escaping the characters Gherkin gives a meaning to
+func tableRow(cells []string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", `\n`)
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = escaper.Replace(cell)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+// hasKeyword reports whether a trimmed line starts with one of the keywords
+func hasKeyword(line string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.HasPrefix(line, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// leadingSpace returns the indentation of a line
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
@@ -0,0 +1,192 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"test-in-go/utils/data_helpers"
+	"testing"
+)
+
+// useDataFiles points data_helpers.DataDir at a temporary directory holding the given files
+func useDataFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	previous := data_helpers.DataDir
+	data_helpers.DataDir = dir
+	t.Cleanup(func() { data_helpers.DataDir = previous })
+}
+
+// featureLines joins lines into the source of a feature file
+func featureLines(lines ...string) string {
+	return strings.Join(lines, "\n")
+}
+
+func TestExpandFeature(t *testing.T) {
+	useDataFiles(t, map[string]string{
+		"products.csv": "code,name\nA,Peas\nB,\"Beans | 1kg\"\n",
+		"more.json":    `[{"code": "C", "name": "Corn"}]`,
+		"empty.csv":    "code,name\n",
+	})
+
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "feature without data tags is unchanged",
+			source: featureLines("Feature: Products", "", "  Scenario: Create", "    Given a product", ""),
+			want:   featureLines("Feature: Products", "", "  Scenario: Create", "    Given a product", ""),
+		},
+		{
+			name: "examples go before the tags and blank lines of the next scenario",
+			source: featureLines(
+				"Feature: Products",
+				"",
+				"  @data(products.csv)",
+				"  Scenario Outline: Create",
+				"    Given a product \"<code>\"",
+				"",
+				"  @smoke",
+				"  Scenario: Other",
+				"    Given nothing",
+			),
+			want: featureLines(
+				"Feature: Products",
+				"",
+				"  @data(products.csv)",
+				"  Scenario Outline: Create",
+				"    Given a product \"<code>\"",
+				"",
+				"    Examples: products.csv",
+				"      | code | name |",
+				"      | A | Peas |",
+				`      | B | Beans \| 1kg |`,
+				"",
+				"  @smoke",
+				"  Scenario: Other",
+				"    Given nothing",
+			),
+		},
+		{
+			name: "written examples come first and every data file adds a table",
+			source: featureLines(
+				"Feature: Products",
+				"  @data(products.csv) @data(more.json)",
+				"  Scenario Outline: Create",
+				"    Given a product \"<code>\"",
+				"    Examples:",
+				"      | code | name |",
+				"      | Z | Zucchini |",
+			),
+			want: featureLines(
+				"Feature: Products",
+				"  @data(products.csv) @data(more.json)",
+				"  Scenario Outline: Create",
+				"    Given a product \"<code>\"",
+				"    Examples:",
+				"      | code | name |",
+				"      | Z | Zucchini |",
+				"",
+				"    Examples: products.csv",
+				"      | code | name |",
+				"      | A | Peas |",
+				`      | B | Beans \| 1kg |`,
+				"",
+				"    Examples: more.json",
+				"      | code | name |",
+				"      | C | Corn |",
+			),
+		},
+		{
+			name: "keywords inside doc strings do not end the scenario",
+			source: featureLines(
+				"Feature: Products",
+				"  @data(more.json)",
+				"  Scenario Outline: Create",
+				"    Given the message:",
+				"      \"\"\"",
+				"      Scenario: not a keyword here",
+				"      \"\"\"",
+			),
+			want: featureLines(
+				"Feature: Products",
+				"  @data(more.json)",
+				"  Scenario Outline: Create",
+				"    Given the message:",
+				"      \"\"\"",
+				"      Scenario: not a keyword here",
+				"      \"\"\"",
+				"",
+				"    Examples: more.json",
+				"      | code | name |",
+				"      | C | Corn |",
+			),
+		},
+		{
+			name: "data tag on examples",
+			source: featureLines(
+				"Feature: Products",
+				"  Scenario Outline: Create",
+				"    Given a product \"<code>\"",
+				"    @data(products.csv)",
+				"    Examples:",
+			),
+			wantErr: true,
+		},
+		{
+			name:    "missing data file",
+			source:  featureLines("Feature: Products", "  @data(missing.csv)", "  Scenario Outline: Create", "    Given a product"),
+			wantErr: true,
+		},
+		{
+			name:    "data file without rows",
+			source:  featureLines("Feature: Products", "  @data(empty.csv)", "  Scenario Outline: Create", "    Given a product"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandFeature(tt.source, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandFeature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("expandFeature() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandFeatureRowPlaceholders(t *testing.T) {
+	useDataFiles(t, map[string]string{"random.csv": "description\nData Product {{random4Digit}}\nData Product {{random4Digit}}\n"})
+	source := featureLines("Feature: Products", "  @data(random.csv)", "  Scenario Outline: Create", "    Given a product \"<description>\"")
+
+	first, err := expandFeature(source, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(first, "{{random4Digit}}") {
+		t.Errorf("placeholders were not resolved:\n%s", first)
+	}
+	// Expanding again, as the test ID scan and the step catalogue do, gives the same values
+	if second, _ := expandFeature(source, true); second != first {
+		t.Errorf("expanding twice gave different rows:\n%s\nand\n%s", first, second)
+	}
+
+	raw, err := expandFeature(source, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(raw, "| Data Product {{random4Digit}} |") != 2 {
+		t.Errorf("unresolved expansion should keep the cells as written:\n%s", raw)
+	}
+}
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"test-in-go/utils/data_helpers"
+	"test-in-go/utils/db_helpers"
+	"test-in-go/utils/report_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// Generic step: Assert that a table on the default database contains the rows of a Gherkin table.
+func theTableShouldContain(ctx context.Context, table string, rows *godog.Table) error {
+	return theTableInDatabaseShouldContain(ctx, table, db_helpers.DefaultDatasource, rows)
+}
+
+// Generic step: Assert that a table on a named datasource contains the rows of a Gherkin table.
+// The first row holds column names; key columns are prefixed with "*" (the first column is the key when none is marked).
+func theTableInDatabaseShouldContain(ctx context.Context, table, datasource string, rows *godog.Table) error {
+	stepName := "Validate table contents in the database"
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("Table: %s | Database: %s", table, datasource))
+
+	keyColumns, expectedRows, err := parseExpectedRows(rows)
+	if err == nil {
+		var manager *db_helpers.DBManager
+		manager, err = db_helpers.DatasourceFor(ctx, datasource)
+		if err == nil {
+			err = manager.AssertTableContains(table, keyColumns, expectedRows)
+		}
+	}
+	if err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", err.Error())
+		return err
+	}
+
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", fmt.Sprintf("%d row(s) matched in %s", len(expectedRows), table))
+	return nil
+}
+
+// parseExpectedRows converts a Gherkin table into key columns and expected rows with placeholders resolved
+func parseExpectedRows(rows *godog.Table) ([]string, []db_helpers.ExpectedRow, error) {
+	if rows == nil || len(rows.Rows) < 2 {
+		return nil, nil, fmt.Errorf("the table must have a header row and at least one data row")
+	}
+
+	var columns, keyColumns []string
+	for _, cell := range rows.Rows[0].Cells {
+		column := strings.TrimSpace(cell.Value)
+		if strings.HasPrefix(column, "*") {
+			column = strings.TrimPrefix(column, "*")
+			keyColumns = append(keyColumns, column)
+		}
+		columns = append(columns, column)
+	}
+	if len(keyColumns) == 0 {
+		keyColumns = columns[:1]
+	}
+
+	var expectedRows []db_helpers.ExpectedRow
+	for _, row := range rows.Rows[1:] {
+		expected := make(db_helpers.ExpectedRow, len(columns))
+		for i, cell := range row.Cells {
+			expected[columns[i]] = data_helpers.ResolvePlaceholders(cell.Value)
+		}
+		expectedRows = append(expectedRows, expected)
+	}
+	return keyColumns, expectedRows, nil
+}
+
+// InitializeDatabaseSteps registers the generic database step definitions.
+func InitializeDatabaseSteps(ctx *godog.ScenarioContext) {
+	ctx.Step(`^the "([^"]*)" table should contain:$`, theTableShouldContain)
+	ctx.Step(`^the "([^"]*)" table in database "([^"]*)" should contain:$`, theTableInDatabaseShouldContain)
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"test-in-go/utils/data_helpers"
+	"test-in-go/utils/report_helpers"
+	responsehelpers "test-in-go/utils/response_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// theVariableIs sets a scenario variable, read back with ${name} or ${var:name}
+func theVariableIs(name, value string) error {
+	data_helpers.SetVariable(name, value)
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep("Set variable", "Passed", fmt.Sprintf("%s = %s", name, value))
+	return nil
+}
+
+// InitializeExpressionHooks expands ${...} expressions in the text, doc string and table of every step before
+// the step is matched, so step functions receive the values. Variables and responses are kept per scenario.
+func InitializeExpressionHooks(ctx *godog.ScenarioContext) {
+	ctx.Step(`^the variable "([^"]*)" is "([^"]*)"$`, theVariableIs)
+
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		data_helpers.ResetVariables()
+		responsehelpers.ClearResponses()
+		return ctx, nil
+	})
+
+	ctx.StepContext().Before(func(ctx context.Context, st *godog.Step) (context.Context, error) {
+		return ctx, expandStep(st)
+	})
+}
+
+// expandStep replaces the expressions of a step in place
+func expandStep(st *godog.Step) error {
+	text, err := data_helpers.ExpandExpressions(st.Text)
+	if err != nil {
+		return err
+	}
+	st.Text = text
+
+	if st.Argument == nil {
+		return nil
+	}
+	if docString := st.Argument.DocString; docString != nil {
+		if docString.Content, err = data_helpers.ExpandExpressions(docString.Content); err != nil {
+			return err
+		}
+	}
+	if table := st.Argument.DataTable; table != nil {
+		for _, row := range table.Rows {
+			for _, cell := range row.Cells {
+				if cell.Value, err = data_helpers.ExpandExpressions(cell.Value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"test-in-go/utils/data_helpers"
+	"test-in-go/utils/protocol_helpers"
+	"test-in-go/utils/report_helpers"
+	"time"
+
+	"github.com/cucumber/godog"
+)
+
+// injectFault registers a fault with the proxy in front of the API and reports it
+func injectFault(description string, fault protocol_helpers.Fault, times string) error {
+	stepName := "Inject API fault"
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("%s | Route: %s", description, fault.Route))
+
+	fault.Route = data_helpers.ResolvePlaceholders(fault.Route)
+	if times != "" {
+		n, err := strconv.Atoi(times)
+		if err != nil || n < 1 {
+			report_helpers.FailedStep()
+			report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Invalid number of times: %q", times))
+			return fmt.Errorf("invalid number of times %q", times)
+		}
+		fault.Times = n
+	}
+
+	if err := protocol_helpers.InjectFault(fault); err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
+		return err
+	}
+
+	applies := "every request"
+	if fault.Times > 0 {
+		applies = fmt.Sprintf("the next %d request(s)", fault.Times)
+	}
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", fmt.Sprintf("%s for %s", description, applies))
+	return nil
+}
+
+// Generic step: Answer requests of a route with an error status instead of forwarding them.
+func theAPIRespondsWithFor(status int, route, times string) error {
+	return injectFault(fmt.Sprintf("Respond with %d", status), protocol_helpers.Fault{Route: route, Kind: protocol_helpers.FaultStatus, Status: status}, times)
+}
+
+// Generic step: Delay requests of a route before forwarding them.
+func theAPIIsDelayedByMsFor(delay int, route, times string) error {
+	return injectFault(fmt.Sprintf("Delay by %d ms", delay), protocol_helpers.Fault{Route: route, Kind: protocol_helpers.FaultDelay, Delay: time.Duration(delay) * time.Millisecond}, times)
+}
+
+// Generic step: Close the connection of requests of a route without answering.
+func theAPIDropsTheConnectionFor(route, times string) error {
+	return injectFault("Drop the connection", protocol_helpers.Fault{Route: route, Kind: protocol_helpers.FaultDrop}, times)
+}
+
+// Generic step: Forward requests of a route and truncate the body of their responses.
+func theAPIReturnsACorruptedBodyFor(route, times string) error {
+	return injectFault("Corrupt the response body", protocol_helpers.Fault{Route: route, Kind: protocol_helpers.FaultCorrupt}, times)
+}
+
+// InitializeFaultSteps registers the fault injection steps. The routes are paths below the API base URL,
+// optionally with a method ("POST /product"), and faults are removed after every scenario.
+func InitializeFaultSteps(ctx *godog.ScenarioContext) {
+	ctx.After(func(ctx context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		protocol_helpers.ResetFaults()
+		return ctx, nil
+	})
+
+	ctx.Step(`^the API responds with (\d+) for "([^"]*)"(?: (\d+) times?)?$`, theAPIRespondsWithFor)
+	ctx.Step(`^the API is delayed by (\d+) ms for "([^"]*)"(?: (\d+) times?)?$`, theAPIIsDelayedByMsFor)
+	ctx.Step(`^the API drops the connection for "([^"]*)"(?: (\d+) times?)?$`, theAPIDropsTheConnectionFor)
+	ctx.Step(`^the API returns a corrupted body for "([^"]*)"(?: (\d+) times?)?$`, theAPIReturnsACorruptedBodyFor)
+}
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"test-in-go/config"
+	"test-in-go/utils/data_helpers"
+	"test-in-go/utils/db_helpers"
+	"test-in-go/utils/report_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// InitializeFixtureSuite applies the configured suite fixtures before the run and the teardown fixtures after it.
+func InitializeFixtureSuite(ctx *godog.TestSuiteContext) {
+	ctx.BeforeSuite(func() {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		for _, name := range cfg.Fixtures.Suite {
+			if err := db_helpers.ApplyFixture(context.Background(), name); err != nil {
+				fmt.Printf("Error applying suite fixture: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	})
+
+	ctx.AfterSuite(func() {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return
+		}
+		for _, name := range cfg.Fixtures.Teardown {
+			if err := db_helpers.ApplyFixture(context.Background(), name); err != nil {
+				fmt.Printf("Error applying teardown fixture: %v\n", err)
+			}
+		}
+	})
+}
+
+// InitializeFixtureHooks loads @fixture(name) data before each scenario and removes the rows it created afterwards.
+func InitializeFixtureHooks(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		data_helpers.ForgetScenarioTestCode()
+		for _, name := range tagArguments(sc, "fixture") {
+			if err := db_helpers.ApplyFixture(ctx, name); err != nil {
+				return ctx, err
+			}
+			report_helpers.PrettyLogStep("Load fixture", "Passed", fmt.Sprintf("Fixture: %s", name))
+		}
+		return ctx, nil
+	})
+
+	ctx.After(func(ctx context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		// Rolled back scenarios leave nothing behind, and prefix deletes could touch parallel runs
+		if hasTag(sc, transactionTag) {
+			db_helpers.DiscardCreatedRows()
+			return ctx, nil
+		}
+		return ctx, cleanupScenarioRows()
+	})
+}
+
+// cleanupScenarioRows deletes the recorded rows and the rows matching the configured cleanup prefixes.
+// Prefixes are only applied when the scenario set its own TestCode; otherwise they would match the rows
+// of the default test code or of the previous scenario.
+func cleanupScenarioRows() error {
+	cleanupErr := db_helpers.CleanupCreatedRows()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if !data_helpers.ScenarioHasTestCode() {
+		if cleanupErr != nil {
+			report_helpers.PrettyLogStep("Clean up scenario data", "Failed", cleanupErr.Error())
+		}
+		return cleanupErr
+	}
+	for _, rule := range cfg.Fixtures.Cleanup {
+		datasource := rule.Datasource
+		if datasource == "" {
+			datasource = db_helpers.DefaultDatasource
+		}
+		prefix := data_helpers.ResolvePlaceholders(rule.Prefix)
+		if err := db_helpers.DeleteRowsWithPrefix(datasource, rule.Table, rule.Column, prefix); err != nil && cleanupErr == nil {
+			cleanupErr = err
+		}
+	}
+
+	if cleanupErr != nil {
+		report_helpers.PrettyLogStep("Clean up scenario data", "Failed", cleanupErr.Error())
+	}
+	return cleanupErr
+}
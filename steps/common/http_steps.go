@@ -0,0 +1,211 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"test-in-go/utils/data_helpers"
+	"test-in-go/utils/db_helpers"
+	"test-in-go/utils/protocol_helpers"
+	"test-in-go/utils/report_helpers"
+	responsehelpers "test-in-go/utils/response_helpers"
+	validationhelpers "test-in-go/utils/validation_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// Headers added to the requests of the current scenario
+var requestHeaders = map[string]string{}
+
+// Generic step: Add a header to the following requests of the scenario, e.g. "Accept" set to "application/json".
+func iSetTheRequestHeaderTo(name, value string) error {
+	requestHeaders[name] = value
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep("Set request header", "Passed", fmt.Sprintf("%s: %s", name, value))
+	return nil
+}
+
+// Generic step: Send a request without a body, e.g. a "GET" request to "/product/PRD-${testCode}".
+func iSendARequestTo(method, endpoint string) error {
+	return sendRequest(method, endpoint, nil)
+}
+
+// Generic step: Send a request with a doc string body; JSON bodies get a JSON content type unless one is set.
+func iSendARequestToWithBody(method, endpoint string, body *godog.DocString) error {
+	return sendRequest(method, endpoint, []byte(body.Content))
+}
+
+// sendRequest sends a request with the scenario's headers; the response is kept in the response store
+func sendRequest(method, endpoint string, body []byte) error {
+	stepName := "Send HTTP request"
+	method = strings.ToUpper(method)
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("%s %s", method, endpoint))
+
+	headers := make(map[string]string, len(requestHeaders)+1)
+	for name, value := range requestHeaders {
+		headers[name] = value
+	}
+	if _, ok := headers["Content-Type"]; !ok && body != nil {
+		headers["Content-Type"] = "application/json"
+	}
+
+	resp, err := protocol_helpers.SendRawRequest(method, endpoint, body, headers)
+	if err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
+		return err
+	}
+	resp.Body.Close()
+
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", fmt.Sprintf("%s %s answered %d", method, endpoint, resp.StatusCode))
+	return nil
+}
+
+// Generic step: Assert the status code of the last response.
+func theResponseStatusShouldBe(status int) error {
+	stepName := "Validate response status"
+	response, err := responsehelpers.LastResponse()
+	if err == nil && response.Status != status {
+		err = fmt.Errorf("expected response status %d, got %d: %s", status, response.Status, truncate(string(response.Body), 500))
+	}
+	return reportAssertion(stepName, err, fmt.Sprintf("Status %d", status))
+}
+
+// Generic step: Assert a response header, e.g. "Content-Type" should contain "json".
+func theResponseHeaderShould(name, comparison, expected string) error {
+	stepName := "Validate response header"
+	response, err := responsehelpers.LastResponse()
+	if err == nil {
+		var actual string
+		if actual, err = response.Value("header." + name); err == nil {
+			switch {
+			case comparison == "be" && actual != expected:
+				err = fmt.Errorf("expected response header %s to be %q, got %q", name, expected, actual)
+			case comparison == "contain" && !strings.Contains(actual, expected):
+				err = fmt.Errorf("expected response header %s to contain %q, got %q", name, expected, actual)
+			}
+		}
+	}
+	return reportAssertion(stepName, err, fmt.Sprintf("%s should %s %q", name, comparison, expected))
+}
+
+// Generic step: Assert a field of the JSON response; the expectation may use the operators of table assertions,
+// e.g. "PRD-${testCode}", "~ ^[0-9a-f-]{36}$", "!= 0", "barcode EAN13" or "<not null>".
+func theResponseFieldShouldBe(path, expectation string) error {
+	stepName := "Validate response field"
+	err := matchResponseField(path, expectation)
+	return reportAssertion(stepName, err, fmt.Sprintf("%s is %s", path, expectation))
+}
+
+// Generic step: Assert several fields of the JSON response, one "JSONPath | expectation" row each.
+func theResponseShouldContain(table *godog.Table) error {
+	stepName := "Validate response fields"
+	var failures []string
+	for i, row := range table.Rows {
+		if len(row.Cells) != 2 {
+			return reportAssertion(stepName, fmt.Errorf("row %d must have 2 cells (field | expectation), got %d", i+1, len(row.Cells)), "")
+		}
+		path, expectation := row.Cells[0].Value, row.Cells[1].Value
+		if i == 0 && path == "field" && expectation == "value" {
+			continue // Optional header row
+		}
+		if err := matchResponseField(path, expectation); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	var err error
+	if len(failures) > 0 {
+		err = fmt.Errorf("%d response field(s) did not match:\n  - %s", len(failures), strings.Join(failures, "\n  - "))
+	}
+	return reportAssertion(stepName, err, fmt.Sprintf("%d field(s) matched", len(table.Rows)))
+}
+
+// matchResponseField compares one JSONPath of the last response with an expectation
+func matchResponseField(path, expectation string) error {
+	response, err := responsehelpers.LastResponse()
+	if err != nil {
+		return err
+	}
+	document, err := validationhelpers.ParseJSON(response.Body)
+	if err != nil {
+		return fmt.Errorf("response body: %v", err)
+	}
+	value, err := validationhelpers.GetJSONPathValue(document, path)
+	if err != nil {
+		return err
+	}
+
+	var actual interface{}
+	if value != nil {
+		actual = validationhelpers.FormatJSONValue(value)
+	}
+	expectation = data_helpers.ResolvePlaceholders(expectation)
+	matched, err := db_helpers.MatchValue(expectation, actual)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if !matched {
+		return fmt.Errorf("%s: expected %s, got %s", path, expectation, db_helpers.FormatValue(actual))
+	}
+	return nil
+}
+
+// Generic step: Store a part of the last response as a scenario variable, read back with ${name} or ${var:name}.
+// The part is a JSONPath such as "$.id", "status" or "header.Location".
+func iStoreAs(selector, name string) error {
+	stepName := "Store response value"
+	response, err := responsehelpers.LastResponse()
+	var value string
+	if err == nil {
+		value, err = response.Value(selector)
+	}
+	if err == nil {
+		data_helpers.SetVariable(name, value)
+	}
+	return reportAssertion(stepName, err, fmt.Sprintf("%s = %s", name, value))
+}
+
+// Generic step: Keep the last response under a name, e.g. before sending the next request.
+func iSaveTheResponseAs(name string) error {
+	return reportAssertion("Save response", responsehelpers.SaveResponse(name), fmt.Sprintf("Saved as %s", name))
+}
+
+// reportAssertion logs the result of a generic step and returns its error
+func reportAssertion(stepName string, err error, details string) error {
+	if err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", err.Error())
+		return err
+	}
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", details)
+	return nil
+}
+
+// truncate shortens long response bodies in error messages
+func truncate(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+	return text[:length] + "... (" + strconv.Itoa(len(text)-length) + " more bytes)"
+}
+
+// InitializeHTTPSteps registers the generic HTTP steps, which cover any endpoint of the API without Go code.
+func InitializeHTTPSteps(ctx *godog.ScenarioContext) {
+	ctx.Step(`^I set the request header "([^"]*)" to "([^"]*)"$`, iSetTheRequestHeaderTo)
+	ctx.Step(`^I send a "([^"]*)" request to "([^"]*)"$`, iSendARequestTo)
+	ctx.Step(`^I send a "([^"]*)" request to "([^"]*)" with body:$`, iSendARequestToWithBody)
+	ctx.Step(`^the response status should be (\d+)$`, theResponseStatusShouldBe)
+	ctx.Step(`^the response header "([^"]*)" should (be|contain) "([^"]*)"$`, theResponseHeaderShould)
+	ctx.Step(`^the response field "([^"]*)" should be "([^"]*)"$`, theResponseFieldShouldBe)
+	ctx.Step(`^the response should contain:$`, theResponseShouldContain)
+	ctx.Step(`^I store "([^"]*)" as "([^"]*)"$`, iStoreAs)
+	ctx.Step(`^I save the response as "([^"]*)"$`, iSaveTheResponseAs)
+
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		requestHeaders = map[string]string{}
+		return ctx, nil
+	})
+}
@@ -0,0 +1,23 @@
+package common
+
+import (
+	"context"
+	"strings"
+	"test-in-go/utils/data_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// InitializeSeedHooks restarts the random data generator for every scenario from the run seed and the scenario,
+// so that rerunning a failing scenario with --seed generates the same data, alone or within the suite.
+func InitializeSeedHooks(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		// The step texts tell the rows of a Scenario Outline apart, as they share the scenario name
+		key := []string{sc.Uri, sc.Name}
+		for _, step := range sc.Steps {
+			key = append(key, step.Text)
+		}
+		data_helpers.SeedScenario(strings.Join(key, "\n"))
+		return ctx, nil
+	})
+}
@@ -0,0 +1,166 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"test-in-go/utils/data_helpers"
+	"test-in-go/utils/db_helpers"
+	"test-in-go/utils/report_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// snapshotTagPattern parses the argument of @snapshot([datasource:]table.keyColumn=prefix)
+var snapshotTagPattern = regexp.MustCompile(`^(?:(\w+):)?(\w+(?:\.\w+)?)\.(\w+)=(.+)$`)
+
+// scenarioSnapshots holds the baseline snapshots of a scenario, keyed by table, the changes detected since them
+// and the rows observed by its @snapshot tags. It travels in the scenario's context, so parallel scenarios keep their own.
+type scenarioSnapshots struct {
+	baselines map[string]*db_helpers.TableSnapshot
+	diffs     map[string]db_helpers.SnapshotDiff
+	observed  []string
+}
+
+// snapshotsKey is the context key of the scenario snapshots
+type snapshotsKey struct{}
+
+// snapshotsFrom returns the snapshots of the scenario running with ctx
+func snapshotsFrom(ctx context.Context) (*scenarioSnapshots, error) {
+	snapshots, ok := ctx.Value(snapshotsKey{}).(*scenarioSnapshots)
+	if !ok {
+		return nil, fmt.Errorf("the snapshot steps are not initialized for this scenario")
+	}
+	return snapshots, nil
+}
+
+// Generic step: Snapshot the rows of a table whose key column starts with a prefix.
+func iTakeASnapshotOfTheTableWhereStartsWith(ctx context.Context, table, keyColumn, prefix string) error {
+	stepName := "Take database snapshot"
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("Table: %s | Key: %s | Prefix: %s", table, keyColumn, prefix))
+
+	snapshots, err := snapshotsFrom(ctx)
+	var snapshot *db_helpers.TableSnapshot
+	if err == nil {
+		snapshot, err = db_helpers.TakeSnapshot(ctx, db_helpers.DefaultDatasource, table, keyColumn, data_helpers.ResolvePlaceholders(prefix))
+	}
+	if err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
+		return err
+	}
+	snapshots.baselines[table] = snapshot
+	delete(snapshots.diffs, table)
+
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", fmt.Sprintf("%d row(s) captured", len(snapshot.Rows)))
+	return nil
+}
+
+// Generic step: Assert the number of rows inserted, updated and deleted since the snapshot of a table.
+func theTableShouldHaveInsertedUpdatedAndDeletedRows(ctx context.Context, table string, inserted, updated, deleted int) error {
+	stepName := "Validate database changes"
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("Table: %s | Expected: %d inserted, %d updated, %d deleted", table, inserted, updated, deleted))
+
+	diff, err := currentSnapshotDiff(ctx, table)
+	if err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
+		return err
+	}
+	if len(diff.Inserted) != inserted || len(diff.Updated) != updated || len(diff.Deleted) != deleted {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", diff.String())
+		return fmt.Errorf("expected %d inserted, %d updated and %d deleted rows, got %s", inserted, updated, deleted, diff.String())
+	}
+
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", diff.String())
+	return nil
+}
+
+// currentSnapshotDiff returns the changes detected around the last observed When step,
+// or compares the table with its baseline snapshot when the changes were not observed by a tag.
+func currentSnapshotDiff(ctx context.Context, table string) (db_helpers.SnapshotDiff, error) {
+	snapshots, err := snapshotsFrom(ctx)
+	if err != nil {
+		return db_helpers.SnapshotDiff{}, err
+	}
+	if diff, ok := snapshots.diffs[table]; ok {
+		return diff, nil
+	}
+	baseline, ok := snapshots.baselines[table]
+	if !ok {
+		return db_helpers.SnapshotDiff{}, fmt.Errorf("no snapshot was taken of the %q table", table)
+	}
+	after, err := baseline.Retake()
+	if err != nil {
+		return db_helpers.SnapshotDiff{}, err
+	}
+	return baseline.Diff(after), nil
+}
+
+// InitializeSnapshotSteps registers the snapshot steps and the @snapshot(...) tag, which snapshots the given rows
+// before and after every When step and writes the inserted, updated and deleted rows to the report.
+func InitializeSnapshotSteps(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		snapshots := &scenarioSnapshots{
+			baselines: make(map[string]*db_helpers.TableSnapshot),
+			diffs:     make(map[string]db_helpers.SnapshotDiff),
+			observed:  tagArguments(sc, "snapshot"),
+		}
+		for _, argument := range snapshots.observed {
+			if !snapshotTagPattern.MatchString(argument) {
+				return ctx, fmt.Errorf("invalid @snapshot(%s): expected [datasource:]table.keyColumn=prefix", argument)
+			}
+		}
+		return context.WithValue(ctx, snapshotsKey{}, snapshots), nil
+	})
+
+	ctx.StepContext().Before(func(ctx context.Context, st *godog.Step) (context.Context, error) {
+		snapshots, err := snapshotsFrom(ctx)
+		if err != nil || st.Type != "Action" {
+			return ctx, nil
+		}
+		for _, argument := range snapshots.observed {
+			match := snapshotTagPattern.FindStringSubmatch(argument)
+			datasource := match[1]
+			if datasource == "" {
+				datasource = db_helpers.DefaultDatasource
+			}
+			snapshot, err := db_helpers.TakeSnapshot(ctx, datasource, match[2], match[3], data_helpers.ResolvePlaceholders(match[4]))
+			if err != nil {
+				return ctx, err
+			}
+			snapshots.baselines[match[2]] = snapshot
+		}
+		return ctx, nil
+	})
+
+	ctx.StepContext().After(func(ctx context.Context, st *godog.Step, status godog.StepResultStatus, err error) (context.Context, error) {
+		snapshots, snapshotsErr := snapshotsFrom(ctx)
+		if snapshotsErr != nil || st.Type != "Action" || len(snapshots.observed) == 0 {
+			return ctx, nil
+		}
+		tables := make([]string, 0, len(snapshots.baselines))
+		for table := range snapshots.baselines {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			baseline := snapshots.baselines[table]
+			after, snapshotErr := baseline.Retake()
+			if snapshotErr != nil {
+				return ctx, snapshotErr
+			}
+			diff := baseline.Diff(after)
+			snapshots.diffs[table] = diff
+			report_helpers.PrettyLogStep("Database changes after: "+st.Text, "Info", diff.String())
+		}
+		return ctx, nil
+	})
+
+	ctx.Step(`^I take a snapshot of the "([^"]*)" table where "([^"]*)" starts with "([^"]*)"$`, iTakeASnapshotOfTheTableWhereStartsWith)
+	ctx.Step(`^the "([^"]*)" table should have (\d+) inserted, (\d+) updated and (\d+) deleted rows?$`, theTableShouldHaveInsertedUpdatedAndDeletedRows)
+}
@@ -0,0 +1,205 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"test-in-go/utils/report_helpers"
+
+	"github.com/cucumber/gherkin/go/v26"
+	"github.com/cucumber/godog"
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+// stepRegistrations are the godog methods that register a step definition
+var stepRegistrations = map[string]bool{"Step": true, "Given": true, "When": true, "Then": true}
+
+// BuildStepCatalogue lists the step definitions registered in the Go files under stepsDir and matches them
+// against the steps of the features under featurePaths, including the Examples generated from @data files.
+func BuildStepCatalogue(stepsDir string, featurePaths []string) (*report_helpers.StepCatalogue, error) {
+	definitions, err := findStepDefinitions(stepsDir)
+	if err != nil {
+		return nil, err
+	}
+	uses, err := findStepUses(featurePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make([]*regexp.Regexp, len(definitions))
+	for i, definition := range definitions {
+		if patterns[i], err = regexp.Compile(definition.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid step pattern %q at %s:%d: %v", definition.Pattern, definition.File, definition.Line, err)
+		}
+	}
+
+	catalogue := &report_helpers.StepCatalogue{Definitions: definitions}
+	for _, use := range uses {
+		matched := false
+		for i, pattern := range patterns {
+			if pattern.MatchString(use.Text) {
+				catalogue.Definitions[i].Uses = append(catalogue.Definitions[i].Uses, use)
+				matched = true
+			}
+		}
+		if !matched {
+			catalogue.Undefined = append(catalogue.Undefined, use)
+		}
+	}
+	return catalogue, nil
+}
+
+// findStepDefinitions reads the ctx.Step calls of the Go files under dir, in file and line order
+func findStepDefinitions(dir string) ([]report_helpers.CataloguedStep, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("could not parse %s: %v", path, err)
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read step definitions in %s: %v", dir, err)
+	}
+
+	// Step functions are reported where they are declared, which may be another file of the package
+	declarations := make(map[string]token.Position)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if function, ok := decl.(*ast.FuncDecl); ok && function.Recv == nil {
+				declarations[packageDir(fset, file)+"."+function.Name.Name] = fset.Position(function.Pos())
+			}
+		}
+	}
+
+	var definitions []report_helpers.CataloguedStep
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !stepRegistrations[selector.Sel.Name] {
+				return true
+			}
+			literal, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				return true
+			}
+			pattern, err := strconv.Unquote(literal.Value)
+			if err != nil {
+				return true
+			}
+
+			position := fset.Position(call.Pos())
+			definition := report_helpers.CataloguedStep{Pattern: pattern, File: position.Filename, Line: position.Line}
+			switch handler := call.Args[1].(type) {
+			case *ast.Ident:
+				definition.Function = handler.Name
+				if declared, ok := declarations[packageDir(fset, file)+"."+handler.Name]; ok {
+					definition.File, definition.Line = declared.Filename, declared.Line
+				}
+			case *ast.SelectorExpr:
+				definition.Function = fmt.Sprintf("%s.%s", handler.X, handler.Sel.Name)
+			case *ast.FuncLit:
+				definition.Function = "func literal"
+			default:
+				definition.Function = "?"
+			}
+			definitions = append(definitions, definition)
+			return true
+		})
+	}
+	return definitions, nil
+}
+
+// packageDir identifies the package of a parsed file by its directory
+func packageDir(fset *token.FileSet, file *ast.File) string {
+	return filepath.Dir(fset.Position(file.Package).Filename)
+}
+
+// findStepUses lists the distinct steps of the features, one per step line and text;
+// the rows of a Scenario Outline give one use per distinct text. Data files are shown as written, not with generated values.
+func findStepUses(featurePaths []string) ([]report_helpers.StepUse, error) {
+	contents, paths, err := expandDataExamples(featurePaths, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read feature %s: %v", path, err)
+		}
+		contents = append(contents, godog.Feature{Name: path, Contents: source})
+	}
+	sort.Slice(contents, func(i, j int) bool { return contents[i].Name < contents[j].Name })
+
+	var uses []report_helpers.StepUse
+	for _, feature := range contents {
+		newID := (&messages.Incrementing{}).NewId
+		document, err := gherkin.ParseGherkinDocument(bytes.NewReader(feature.Contents), newID)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse feature %s: %v", feature.Name, err)
+		}
+		lines := stepLines(document.Feature)
+		seen := make(map[string]bool)
+		for _, pickle := range gherkin.Pickles(*document, feature.Name, newID) {
+			for _, step := range pickle.Steps {
+				use := report_helpers.StepUse{Text: step.Text, File: feature.Name, Line: lines[step.AstNodeIds[0]]}
+				key := fmt.Sprintf("%d %s", use.Line, use.Text)
+				if !seen[key] {
+					seen[key] = true
+					uses = append(uses, use)
+				}
+			}
+		}
+	}
+	return uses, nil
+}
+
+// stepLines maps the IDs of the steps of a feature to their line numbers
+func stepLines(feature *messages.Feature) map[string]int {
+	lines := make(map[string]int)
+	if feature == nil {
+		return lines
+	}
+	addSteps := func(steps []*messages.Step) {
+		for _, step := range steps {
+			lines[step.Id] = int(step.Location.Line)
+		}
+	}
+	for _, child := range feature.Children {
+		switch {
+		case child.Background != nil:
+			addSteps(child.Background.Steps)
+		case child.Scenario != nil:
+			addSteps(child.Scenario.Steps)
+		case child.Rule != nil:
+			for _, ruleChild := range child.Rule.Children {
+				if ruleChild.Background != nil {
+					addSteps(ruleChild.Background.Steps)
+				}
+				if ruleChild.Scenario != nil {
+					addSteps(ruleChild.Scenario.Steps)
+				}
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"regexp"
+
+	"github.com/cucumber/godog"
+)
+
+// tagPattern matches parameterised tags such as @fixture(products) or @data(products.csv)
+var tagPattern = regexp.MustCompile(`^@([\w-]+)\((.*)\)$`)
+
+// tagArguments returns the arguments of every @name(argument) tag on the scenario, in order
+func tagArguments(sc *godog.Scenario, name string) []string {
+	var arguments []string
+	for _, tag := range sc.Tags {
+		if match := tagPattern.FindStringSubmatch(tag.Name); match != nil && match[1] == name {
+			arguments = append(arguments, match[2])
+		}
+	}
+	return arguments
+}
+
+// hasTag reports whether the scenario carries the plain tag, e.g. "@db-transaction"
+func hasTag(sc *godog.Scenario, name string) bool {
+	for _, tag := range sc.Tags {
+		if tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"test-in-go/utils/data_helpers"
+
+	"github.com/cucumber/gherkin/go/v26"
+	"github.com/cucumber/godog"
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+// testIDStep matches the step that starts a test case, "a new testcase with ID "110-010-001""
+var testIDStep = regexp.MustCompile(`^a new testcase with ID "([^"]*)"$`)
+
+// testIDUse is a scenario that starts a test case
+type testIDUse struct {
+	uri      string
+	scenario string
+}
+
+// CheckTestIDs reads every scenario of the features before the run and reports test IDs that break the naming
+// convention, and test IDs used by more than one scenario; the rows of a Scenario Outline count as one scenario.
+// Features are given as contents, e.g. from ExpandDataExamples, or as file paths.
+func CheckTestIDs(contents []godog.Feature, paths []string) error {
+	for _, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read feature %s: %v", path, err)
+		}
+		contents = append(contents, godog.Feature{Name: path, Contents: source})
+	}
+
+	uses := make(map[string][]testIDUse)
+	var problems []string
+	for _, feature := range contents {
+		newID := (&messages.Incrementing{}).NewId
+		document, err := gherkin.ParseGherkinDocument(bytes.NewReader(feature.Contents), newID)
+		if err != nil {
+			return fmt.Errorf("could not parse feature %s: %v", feature.Name, err)
+		}
+		for _, pickle := range gherkin.Pickles(*document, feature.Name, newID) {
+			for _, step := range pickle.Steps {
+				match := testIDStep.FindStringSubmatch(step.Text)
+				if match == nil {
+					continue
+				}
+				if err := data_helpers.ValidateTestID(match[1]); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %q: %v", feature.Name, pickle.Name, err))
+				}
+				use := testIDUse{uri: feature.Name, scenario: pickle.Name}
+				if !containsUse(uses[match[1]], use) {
+					uses[match[1]] = append(uses[match[1]], use)
+				}
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(uses))
+	for id := range uses {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if len(uses[id]) < 2 {
+			continue
+		}
+		var scenarios []string
+		for _, use := range uses[id] {
+			scenarios = append(scenarios, fmt.Sprintf("%s: %q", use.uri, use.scenario))
+		}
+		problems = append(problems, fmt.Sprintf("test ID %s is used by %d scenarios: %s", id, len(scenarios), strings.Join(scenarios, ", ")))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%d test ID problem(s):\n  - %s", len(problems), strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// containsUse reports whether a scenario is already listed
+func containsUse(uses []testIDUse, use testIDUse) bool {
+	for _, existing := range uses {
+		if existing == use {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"context"
+	"test-in-go/utils/db_helpers"
+	"test-in-go/utils/report_helpers"
+
+	"github.com/cucumber/godog"
+)
+
+// transactionTag opts a scenario into transactional database isolation
+const transactionTag = "@db-transaction"
+
+// InitializeTransactionHooks wraps @db-transaction scenarios in a transaction on every datasource and rolls it back afterwards.
+// The transactions travel in the scenario's context, so steps that take the context see them and parallel scenarios do not.
+// Only use it for scenarios that seed and assert directly in the database: writes made by the application server
+// through its own connections are neither visible inside the transaction nor rolled back.
+// It must be registered before InitializeFixtureHooks so that @fixture data is loaded inside the transaction.
+func InitializeTransactionHooks(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		if !hasTag(sc, transactionTag) {
+			return ctx, nil
+		}
+		ctx, err := db_helpers.BeginTransactions(ctx)
+		if err != nil {
+			return ctx, err
+		}
+		report_helpers.PrettyLogStep("Begin database transaction", "Passed", "Scenario changes will be rolled back")
+		return ctx, nil
+	})
+
+	ctx.After(func(ctx context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		if !hasTag(sc, transactionTag) {
+			return ctx, nil
+		}
+		ctx, rollbackErr := db_helpers.RollbackTransactions(ctx)
+		if rollbackErr != nil {
+			report_helpers.PrettyLogStep("Roll back database transaction", "Failed", rollbackErr.Error())
+			return ctx, rollbackErr
+		}
+		report_helpers.PrettyLogStep("Roll back database transaction", "Passed", "Scenario changes rolled back")
+		return ctx, nil
+	})
+}
@@ -15,6 +15,12 @@ import (
 // Declare a global variable for the product ID.
 var createdProductID string
 
+// Product configured from a data table, created by "the product is created".
+var configuredProduct *data_helpers.ProductBuilder
+
+// Status code of the last rejected product request.
+var rejectedStatusCode int
+
 // Helper function to handle API requests, now taking the Root structure that includes a products array.
 func postProductToAPI(root data_helpers.Root) (*http.Response, error) {
 	return protocol_helpers.PostRequest("/product", root)
@@ -49,6 +55,57 @@ func aProductWithTheDescriptionIsCreated(description string) error {
 		WithSKU(data_helpers.GenerateSKU()). // Generate dynamic SKU.
 		Build()
 
+	return createProduct(stepName, product)
+}
+
+// Step 2 (variant): Configure a product from a vertical table of JSON field paths and values, applied onto the builder defaults.
+//
+//	Given a product with:
+//	  | shortDescription              | Frozen peas |
+//	  | sku[0].retailPrice.centsValue | 250         |
+func aProductWith(table *godog.Table) error {
+	stepName := "Configure product from table"
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("%d field(s)", len(table.Rows)))
+
+	builder := data_helpers.NewProductBuilder().WithTestCode()
+	for i, row := range table.Rows {
+		if len(row.Cells) != 2 {
+			report_helpers.FailedStep()
+			report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Row %d must have a field and a value", i+1))
+			return fmt.Errorf("row %d must have 2 cells (field | value), got %d", i+1, len(row.Cells))
+		}
+		field, value := row.Cells[0].Value, row.Cells[1].Value
+		if i == 0 && field == "field" && value == "value" {
+			continue // Optional header row
+		}
+		if err := builder.SetField(field, value); err != nil {
+			report_helpers.FailedStep()
+			report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
+			return err
+		}
+	}
+	configuredProduct = builder
+
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", "Product configured")
+	return nil
+}
+
+// Step 2 (variant): Create the product configured by "a product with:".
+func theProductIsCreated() error {
+	stepName := "Create configured product"
+	report_helpers.PrettyLogStep(stepName, "Started", "")
+
+	if configuredProduct == nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", "No product was configured")
+		return fmt.Errorf("no product was configured; use \"a product with:\" first")
+	}
+	return createProduct(stepName, configuredProduct.Build())
+}
+
+// createProduct sends a product wrapped inside a Root structure and records it for cleanup when it was created.
+func createProduct(stepName string, product data_helpers.Product) error {
 	// Wrap the product inside a Root structure (with products array).
 	root := data_helpers.Root{
 		Products: []data_helpers.Product{product},
@@ -81,8 +138,10 @@ func aProductWithTheDescriptionIsCreated(description string) error {
 		return fmt.Errorf("expected status 201 or 200, got %d", resp.StatusCode)
 	}
 
-	// Store the created product's ID.
+	// Store the created product's ID, also as ${productId}, and record it for cleanup after the scenario.
 	createdProductID = product.ProductCode
+	data_helpers.SetVariable("productId", createdProductID)
+	db_helpers.RecordCreatedRow(db_helpers.DefaultDatasource, "product", "productid", createdProductID)
 
 	// Log success.
 	report_helpers.PassedStep()
@@ -92,12 +151,17 @@ func aProductWithTheDescriptionIsCreated(description string) error {
 
 // Step 3: Validate that the product was created successfully with the expected description.
 func theProductShouldBeCreatedSuccessfullyWithDescription(expectedDescription string) error {
+	return theProductShouldBeCreatedSuccessfullyWithDescriptionInDatabase(expectedDescription, db_helpers.DefaultDatasource)
+}
+
+// Step 3 (variant): Validate the product description in a named datasource such as "orders".
+func theProductShouldBeCreatedSuccessfullyWithDescriptionInDatabase(expectedDescription, datasource string) error {
 	stepName := "Validate product creation in the database"
-	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("Expected Description: %s", expectedDescription))
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("Expected Description: %s | Database: %s", expectedDescription, datasource))
 
 	// Define the query to check the product description in the database.
 	query := "SELECT shortDescription FROM product WHERE productid = $1"
-	dbValue, err := db_helpers.QueryDatabase(query, createdProductID)
+	dbValue, err := db_helpers.QueryDatabaseIn(datasource, query, createdProductID)
 	if err != nil {
 		report_helpers.FailedStep()
 		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
@@ -117,10 +181,69 @@ func theProductShouldBeCreatedSuccessfullyWithDescription(expectedDescription st
 	return nil
 }
 
+// Step 4: Send a product broken in one field, e.g. the "productCode" field removed or the "shortDescription" field too long.
+func aProductIsSentWithTheField(fieldPath, breakage string) error {
+	stepName := "Send invalid product"
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("Field: %s | Breakage: %s", fieldPath, breakage))
+
+	// Field paths are relative to the product inside the Root structure.
+	path := "products[0]." + fieldPath
+	payload := data_helpers.NewProductBuilder().
+		WithTestCode().
+		WithSKU(data_helpers.GenerateSKU()).
+		BuildInvalid()
+	switch breakage {
+	case "removed":
+		payload.WithoutField(path)
+	case "of the wrong type":
+		payload.WithWrongType(path)
+	case "too long":
+		payload.WithOversizeString(path, 1001)
+	case "set to an invalid value":
+		payload.WithInvalidEnum(path)
+	}
+	document, err := payload.Build()
+	if err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Error: %v", err))
+		return err
+	}
+
+	resp, err := protocol_helpers.PostRequest("/product", document)
+	if err != nil {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Failed to send API request: %v", err))
+		return err
+	}
+	defer resp.Body.Close()
+	rejectedStatusCode = resp.StatusCode
+
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", fmt.Sprintf("API answered with status %d", resp.StatusCode))
+	return nil
+}
+
+// Step 5: Validate that the invalid product was rejected with the expected status code.
+func theProductShouldBeRejectedWithStatus(expectedStatus int) error {
+	stepName := "Validate product rejection"
+	report_helpers.PrettyLogStep(stepName, "Started", fmt.Sprintf("Expected status: %d", expectedStatus))
+
+	if rejectedStatusCode != expectedStatus {
+		report_helpers.FailedStep()
+		report_helpers.PrettyLogStep(stepName, "Failed", fmt.Sprintf("Expected status %d, got %d", expectedStatus, rejectedStatusCode))
+		return fmt.Errorf("expected status %d, got %d", expectedStatus, rejectedStatusCode)
+	}
+
+	report_helpers.PassedStep()
+	report_helpers.PrettyLogStep(stepName, "Passed", fmt.Sprintf("Product rejected with status %d", rejectedStatusCode))
+	return nil
+}
+
 // InitializeProductSteps registers the step definitions for the scenario.
 func InitializeProductSteps(ctx *godog.ScenarioContext) {
 	ctx.BeforeScenario(func(sc *godog.Scenario) {
 		report_helpers.PrettyLogScenario(sc.Name, "Started")
+		configuredProduct = nil
 		data_helpers.TotalScenarios++
 	})
 
@@ -137,4 +260,9 @@ func InitializeProductSteps(ctx *godog.ScenarioContext) {
 	ctx.Step(`^a new testcase with ID "([^"]*)"$`, aNewTestcaseWithID)
 	ctx.Step(`^a product with the description "([^"]*)" is created$`, aProductWithTheDescriptionIsCreated)
 	ctx.Step(`^the product should be created successfully with description "([^"]*)"$`, theProductShouldBeCreatedSuccessfullyWithDescription)
+	ctx.Step(`^the product should be created successfully with description "([^"]*)" in database "([^"]*)"$`, theProductShouldBeCreatedSuccessfullyWithDescriptionInDatabase)
+	ctx.Step(`^a product with:$`, aProductWith)
+	ctx.Step(`^the product is created$`, theProductIsCreated)
+	ctx.Step(`^a product is sent with the "([^"]*)" field (removed|of the wrong type|too long|set to an invalid value)$`, aProductIsSentWithTheField)
+	ctx.Step(`^the product should be rejected with status (\d+)$`, theProductShouldBeRejectedWithStatus)
 }
@@ -0,0 +1,424 @@
+package codegen_helpers
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	validationhelpers "test-in-go/utils/validation_helpers"
+)
+
+// generatedMarker starts the header of every generated file; files without it are never overwritten
+const generatedMarker = "// Code generated by"
+
+// GenerateOptions selects the schema to generate from and where the Go code is written
+type GenerateOptions struct {
+	Schema  string // JSON Schema or OpenAPI document (JSON or YAML)
+	Type    string // component name in an OpenAPI document, or the type name of a plain JSON Schema
+	OutDir  string // package directory, e.g. utils/data_helpers
+	Package string // package name, defaults to the directory name
+	Force   bool   // overwrite hand-written files
+}
+
+// model is a struct to generate
+type model struct {
+	Name        string
+	Description string
+	Fields      []field
+}
+
+// field is one property of a generated struct
+type field struct {
+	Name     string
+	JSON     string
+	Type     string
+	Default  string
+	ItemType string
+}
+
+// generator collects the models reachable from the root schema
+type generator struct {
+	document map[string]interface{}
+	existing map[string]bool
+	models   []*model
+	byName   map[string]*model
+}
+
+// Generate writes the model structs and fluent builders of a schema and of the object schemas it references.
+// Types that already exist in the output package are reused instead of being generated again.
+// It returns the paths of the written files.
+func Generate(opts GenerateOptions) ([]string, error) {
+	if opts.Type == "" || !isIdentifier(exportName(opts.Type)) {
+		return nil, fmt.Errorf("invalid type name %q", opts.Type)
+	}
+	if opts.Package == "" {
+		opts.Package = filepath.Base(opts.OutDir)
+	}
+
+	document, err := validationhelpers.LoadDocument(opts.Schema)
+	if err != nil {
+		return nil, err
+	}
+	root, err := rootSchema(document, opts.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	base := snakeCase(exportName(opts.Type))
+	modelPath := filepath.Join(opts.OutDir, base+".go")
+	builderPath := filepath.Join(opts.OutDir, base+"_builder.go")
+	for _, path := range []string{modelPath, builderPath} {
+		if err := checkOverwrite(path, opts.Force); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := declaredTypes(opts.OutDir, modelPath, builderPath)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{document: document, existing: existing, byName: make(map[string]*model)}
+	if existing[exportName(opts.Type)] {
+		return nil, fmt.Errorf("type %s is already declared in %s", exportName(opts.Type), opts.OutDir)
+	}
+	if _, err := g.addModel(exportName(opts.Type), root); err != nil {
+		return nil, err
+	}
+
+	header := fmt.Sprintf("%s go run main.go generate -schema %s -type %s; DO NOT EDIT.\n\npackage %s\n",
+		generatedMarker, filepath.ToSlash(opts.Schema), opts.Type, opts.Package)
+	files := map[string]string{
+		modelPath:   header + g.renderModels(),
+		builderPath: header + g.renderBuilders(),
+	}
+	var written []string
+	for _, path := range []string{modelPath, builderPath} {
+		source, err := format.Source([]byte(files[path]))
+		if err != nil {
+			return nil, fmt.Errorf("generated code for %s does not compile: %v", path, err)
+		}
+		if err := os.WriteFile(path, source, 0644); err != nil {
+			return nil, fmt.Errorf("could not write %s: %v", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// rootSchema finds the schema to generate: an OpenAPI component, a JSON Schema definition or the document itself
+func rootSchema(document map[string]interface{}, typeName string) (map[string]interface{}, error) {
+	for _, ref := range []string{"#/components/schemas/" + typeName, "#/$defs/" + typeName, "#/definitions/" + typeName} {
+		if schema, err := validationhelpers.ResolveReference(document, ref); err == nil {
+			return schema, nil
+		}
+	}
+	if _, isOpenAPI := document["openapi"]; isOpenAPI {
+		return nil, fmt.Errorf("component %q not found in components.schemas", typeName)
+	}
+	return document, nil
+}
+
+// addModel registers an object schema as a struct and returns its type name
+func (g *generator) addModel(name string, schema map[string]interface{}) (string, error) {
+	if g.existing[name] || g.byName[name] != nil {
+		return name, nil
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	if len(properties) == 0 {
+		return "", fmt.Errorf("schema %s has no properties to generate a struct from", name)
+	}
+
+	m := &model{Name: name}
+	m.Description, _ = schema["description"].(string)
+	g.byName[name] = m
+	g.models = append(g.models, m)
+
+	for _, property := range propertyOrder(schema) {
+		propertySchema, _ := properties[property].(map[string]interface{})
+		f := field{Name: exportName(property), JSON: property}
+		goType, err := g.goType(propertySchema, name+exportName(property))
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %v", name, property, err)
+		}
+		f.Type = goType
+		if strings.HasPrefix(goType, "[]") {
+			f.ItemType = strings.TrimPrefix(goType, "[]")
+		}
+		f.Default = g.defaultValue(propertySchema, goType)
+		m.Fields = append(m.Fields, f)
+	}
+	return name, nil
+}
+
+// goType maps a property schema to a Go type, generating structs for referenced and inline objects
+func (g *generator) goType(schema map[string]interface{}, inlineName string) (string, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := validationhelpers.ResolveReference(g.document, ref)
+		if err != nil {
+			return "", err
+		}
+		name := exportName(ref[strings.LastIndex(ref, "/")+1:])
+		if resolved["type"] != nil && resolved["type"] != "object" {
+			return g.goType(resolved, name)
+		}
+		return g.addModel(name, resolved)
+	}
+
+	schemaType, _ := schema["type"].(string)
+	switch schemaType {
+	case "string":
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		itemType, err := g.goType(items, inlineName+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + itemType, nil
+	case "object", "":
+		if _, hasProperties := schema["properties"]; hasProperties {
+			return g.addModel(inlineName, schema)
+		}
+		return "map[string]interface{}", nil
+	}
+	return "", fmt.Errorf("unsupported type %q", schemaType)
+}
+
+// defaultValue renders the Go expression a builder starts a field with.
+// Schema defaults win over examples, then the first enum value; strings may use placeholders such as {{testCode}}.
+// Nested structs start from their own builder.
+func (g *generator) defaultValue(schema map[string]interface{}, goType string) string {
+	value, ok := schema["default"]
+	if !ok {
+		value, ok = schema["example"]
+	}
+	if !ok {
+		if enum, isList := schema["enum"].([]interface{}); isList && len(enum) > 0 {
+			value, ok = enum[0], true
+		}
+	}
+
+	switch goType {
+	case "string":
+		if !ok {
+			return ""
+		}
+		text := fmt.Sprint(value)
+		if strings.Contains(text, "{{") {
+			return fmt.Sprintf("ResolvePlaceholders(%q)", text)
+		}
+		return fmt.Sprintf("%q", text)
+	case "int", "float64", "bool":
+		if !ok {
+			return ""
+		}
+		return fmt.Sprint(value)
+	}
+	if strings.HasPrefix(goType, "[]") {
+		return goType + "{}"
+	}
+	if g.byName[goType] != nil {
+		return fmt.Sprintf("New%sBuilder().Build()", goType)
+	}
+	return ""
+}
+
+// renderModels writes the struct declarations
+func (g *generator) renderModels() string {
+	var b bytes.Buffer
+	for _, m := range g.models {
+		b.WriteString("\n")
+		description := m.Description
+		if description == "" {
+			description = "represents the " + m.Name + " payload structure."
+		}
+		fmt.Fprintf(&b, "// %s %s\n", m.Name, lowerFirst(strings.TrimSpace(description)))
+		fmt.Fprintf(&b, "type %s struct {\n", m.Name)
+		for _, f := range m.Fields {
+			fmt.Fprintf(&b, "\t%s %s `json:\"%s\"`\n", f.Name, f.Type, f.JSON)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// renderBuilders writes a fluent builder with defaults for every generated struct
+func (g *generator) renderBuilders() string {
+	var b bytes.Buffer
+	for _, m := range g.models {
+		receiver := lowerFirst(m.Name)
+		fmt.Fprintf(&b, "\n// %sBuilder builds %s payloads starting from the schema defaults.\n", m.Name, m.Name)
+		fmt.Fprintf(&b, "type %sBuilder struct {\n\t%s %s\n}\n\n", m.Name, receiver, m.Name)
+
+		fmt.Fprintf(&b, "func New%sBuilder() *%sBuilder {\n\treturn &%sBuilder{\n\t\t%s: %s{\n", m.Name, m.Name, m.Name, receiver, m.Name)
+		for _, f := range m.Fields {
+			if f.Default != "" {
+				fmt.Fprintf(&b, "\t\t\t%s: %s,\n", f.Name, f.Default)
+			}
+		}
+		b.WriteString("\t\t},\n\t}\n}\n")
+
+		for _, f := range m.Fields {
+			param := parameterName(f.JSON)
+			fmt.Fprintf(&b, "\nfunc (b *%sBuilder) With%s(%s %s) *%sBuilder {\n\tb.%s.%s = %s\n\treturn b\n}\n",
+				m.Name, f.Name, param, f.Type, m.Name, receiver, f.Name, param)
+			if f.ItemType != "" {
+				fmt.Fprintf(&b, "\nfunc (b *%sBuilder) Add%s(item %s) *%sBuilder {\n\tb.%s.%s = append(b.%s.%s, item)\n\treturn b\n}\n",
+					m.Name, singular(f.Name), f.ItemType, m.Name, receiver, f.Name, receiver, f.Name)
+			}
+		}
+		fmt.Fprintf(&b, "\nfunc (b *%sBuilder) Build() %s {\n\treturn b.%s\n}\n", m.Name, m.Name, receiver)
+	}
+	return b.String()
+}
+
+// propertyOrder lists required properties in their declared order, followed by the others alphabetically
+func propertyOrder(schema map[string]interface{}) []string {
+	properties, _ := schema["properties"].(map[string]interface{})
+	seen := make(map[string]bool)
+	var order []string
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, exists := properties[fmt.Sprint(name)]; exists && !seen[fmt.Sprint(name)] {
+				order = append(order, fmt.Sprint(name))
+				seen[fmt.Sprint(name)] = true
+			}
+		}
+	}
+	var rest []string
+	for name := range properties {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(order, rest...)
+}
+
+// declaredTypes returns the type names declared in the package directory, ignoring the files about to be regenerated
+func declaredTypes(dir string, skip ...string) (map[string]bool, error) {
+	types := make(map[string]bool)
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if contains(skip, path) || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", path, err)
+		}
+		for name, object := range file.Scope.Objects {
+			if object.Kind.String() == "type" {
+				types[name] = true
+			}
+		}
+	}
+	return types, nil
+}
+
+// checkOverwrite refuses to replace a file that was not generated, unless forced
+func checkOverwrite(path string, force bool) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || force {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read %s: %v", path, err)
+	}
+	if !strings.HasPrefix(string(data), generatedMarker) {
+		return fmt.Errorf("%s exists and was not generated; use -force to overwrite it", path)
+	}
+	return nil
+}
+
+// exportName turns a JSON property or schema name into an exported Go identifier, e.g. order_line -> OrderLine
+func exportName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// parameterName returns a lower-case parameter name for a JSON property that is not a Go keyword
+func parameterName(jsonName string) string {
+	name := lowerFirst(exportName(jsonName))
+	if token.IsKeyword(name) || !isIdentifier(name) {
+		return name + "Value"
+	}
+	return name
+}
+
+// snakeCase converts OrderLine to order_line (and SKUUom to sku_uom) for file names
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			previousLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || nextLower {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// singular names one item of a list field, e.g. Lines -> Line
+func singular(name string) string {
+	if len(name) > 1 && strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") {
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func isIdentifier(name string) bool {
+	return token.IsIdentifier(name)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package data_helpers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Barcode formats
+const (
+	EAN13   = "EAN13"
+	UPCA    = "UPCA"
+	GTIN14  = "GTIN14"
+	Code128 = "CODE128"
+)
+
+// MaxBarcodeSequence is the highest sequence number a TestCode-derived barcode can carry
+const MaxBarcodeSequence = 99999
+
+// barcodeSequences counts the barcodes handed out by NextBarcode per format and TestCode.
+// Sequence 0 is left for the primary product barcode of the TestCode.
+var (
+	barcodeSequences = make(map[string]int)
+	barcodeMutex     sync.Mutex
+)
+
+// GS1CheckDigit calculates the check digit of a GS1 number (EAN-13, UPC-A, GTIN-14, ...) given without it:
+// from the right, digits are weighted 3, 1, 3, 1, ... and the check digit tops the sum up to a multiple of 10.
+func GS1CheckDigit(digits string) (int, error) {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("%q is not numeric", digits)
+		}
+		weight := 1
+		if (len(digits)-1-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(c-'0') * weight
+	}
+	return (10 - sum%10) % 10, nil
+}
+
+// withGS1CheckDigit appends the check digit to a numeric string generated by this package
+func withGS1CheckDigit(digits string) string {
+	check, _ := GS1CheckDigit(digits)
+	return fmt.Sprintf("%s%d", digits, check)
+}
+
+// GenerateBarcode returns the barcode of a format for the current TestCode and a sequence number (0-99999).
+// The TestCode carries the test round, so barcodes of different TestCodes, rounds, sequences or formats never collide:
+//   - EAN13:   21 + TestCode (5 digits) + sequence (5 digits) + check digit; prefix 21 is reserved for in-store numbering
+//   - UPCA:    2 + TestCode + sequence (5 digits) + check digit
+//   - GTIN14:  packaging indicator 1 + the EAN13 of the sequence without its check digit + check digit
+//   - CODE128: TC + TestCode + "-" + sequence (5 digits)
+func GenerateBarcode(format string, sequence int) (string, error) {
+	if sequence < 0 || sequence > MaxBarcodeSequence {
+		return "", fmt.Errorf("barcode sequence %d is out of range 0-%d", sequence, MaxBarcodeSequence)
+	}
+	code := fmt.Sprintf("%05s", TestCode)
+	if len(code) != 5 || strings.Trim(code, "0123456789") != "" {
+		return "", fmt.Errorf("barcodes need a numeric TestCode of up to 5 digits, got %q", TestCode)
+	}
+	switch strings.ToUpper(format) {
+	case EAN13:
+		return withGS1CheckDigit(fmt.Sprintf("21%s%05d", code, sequence)), nil
+	case UPCA:
+		return withGS1CheckDigit(fmt.Sprintf("2%s%05d", code, sequence)), nil
+	case GTIN14:
+		return withGS1CheckDigit(fmt.Sprintf("121%s%05d", code, sequence)), nil
+	case Code128:
+		return fmt.Sprintf("TC%s-%05d", code, sequence), nil
+	}
+	return "", fmt.Errorf("unknown barcode format %q (expected %s, %s, %s or %s)", format, EAN13, UPCA, GTIN14, Code128)
+}
+
+// NextBarcode returns a barcode of a format with the next unused sequence number of the current TestCode
+func NextBarcode(format string) (string, error) {
+	barcodeMutex.Lock()
+	key := strings.ToUpper(format) + ":" + TestCode
+	barcodeSequences[key]++
+	sequence := barcodeSequences[key]
+	barcodeMutex.Unlock()
+	return GenerateBarcode(format, sequence)
+}
+
+// ProductBarcode returns the primary EAN-13 barcode of the product of the current TestCode
+func ProductBarcode() string {
+	barcode, _ := GenerateBarcode(EAN13, 0)
+	return barcode
+}
+
+// ValidateBarcode checks that a barcode is well formed for its format, including the GS1 check digit.
+// The format "GS1" accepts any of UPC-A (12 digits), EAN-13 (13 digits) and GTIN-14 (14 digits).
+func ValidateBarcode(format, barcode string) error {
+	format = strings.ToUpper(format)
+	if format == Code128 {
+		if barcode == "" || len(barcode) > 48 {
+			return fmt.Errorf("code 128 barcode %q must have 1 to 48 characters", barcode)
+		}
+		for _, c := range barcode {
+			if c < 32 || c > 126 {
+				return fmt.Errorf("code 128 barcode %q contains the non-printable or non-ASCII character %q", barcode, c)
+			}
+		}
+		return nil
+	}
+
+	lengths := map[string]int{UPCA: 12, EAN13: 13, GTIN14: 14}
+	if format == "GS1" {
+		for name, length := range lengths {
+			if len(barcode) == length {
+				format = name
+			}
+		}
+		if format == "GS1" {
+			return fmt.Errorf("GS1 barcode %q must have 12, 13 or 14 digits, got %d", barcode, len(barcode))
+		}
+	}
+	length, ok := lengths[format]
+	if !ok {
+		return fmt.Errorf("unknown barcode format %q (expected %s, %s, %s, GS1 or %s)", format, EAN13, UPCA, GTIN14, Code128)
+	}
+	if len(barcode) != length {
+		return fmt.Errorf("%s barcode %q must have %d digits, got %d", format, barcode, length, len(barcode))
+	}
+	check, err := GS1CheckDigit(barcode[:length-1])
+	if err != nil || barcode[length-1] < '0' || barcode[length-1] > '9' {
+		return fmt.Errorf("%s barcode %q must contain digits only", format, barcode)
+	}
+	if want := byte('0' + check); barcode[length-1] != want {
+		return fmt.Errorf("%s barcode %q has check digit %c, expected %c", format, barcode, barcode[length-1], want)
+	}
+	return nil
+}
+
+// RandomEAN13 returns an EAN-13 barcode with the UK GS1 prefix 50 and a valid check digit
+func RandomEAN13() string {
+	return withGS1CheckDigit("50" + RandomDigits(10))
+}
+
+// RandomGTIN14 returns a GTIN-14 for an outer case of a random EAN-13 item, with a valid check digit.
+// The first digit is the packaging indicator (1-8).
+func RandomGTIN14() string {
+	item := RandomEAN13()
+	return withGS1CheckDigit(fmt.Sprint(RandomInt(1, 8)) + item[:12])
+}
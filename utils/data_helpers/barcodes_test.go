@@ -0,0 +1,123 @@
+package data_helpers
+
+import "testing"
+
+func TestGS1CheckDigit(t *testing.T) {
+	tests := []struct {
+		name    string
+		digits  string
+		want    int
+		wantErr bool
+	}{
+		{name: "EAN-13", digits: "400638133393", want: 1},
+		{name: "EAN-13 with check digit 7", digits: "590123412345", want: 7},
+		{name: "ISBN-13", digits: "978030640615", want: 7},
+		{name: "UPC-A", digits: "03600029145", want: 2},
+		{name: "GTIN-14", digits: "1001234567890", want: 2},
+		{name: "sum already a multiple of 10", digits: "000000000000", want: 0},
+		{name: "non-numeric", digits: "40063813339A", wantErr: true},
+		{name: "space", digits: "4006381 3393", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GS1CheckDigit(tt.digits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GS1CheckDigit(%q) error = %v, wantErr %v", tt.digits, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("GS1CheckDigit(%q) = %d, want %d", tt.digits, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBarcode(t *testing.T) {
+	tests := []struct {
+		format  string
+		barcode string
+		wantErr bool
+	}{
+		{format: EAN13, barcode: "4006381333931"},
+		{format: "ean13", barcode: "5901234123457"},
+		{format: UPCA, barcode: "036000291452"},
+		{format: GTIN14, barcode: "10012345678902"},
+		{format: "GS1", barcode: "036000291452"},
+		{format: "GS1", barcode: "4006381333931"},
+		{format: "GS1", barcode: "10012345678902"},
+		{format: Code128, barcode: "TC20512-00001"},
+		{format: EAN13, barcode: "4006381333932", wantErr: true},
+		{format: EAN13, barcode: "400638133393", wantErr: true},
+		{format: UPCA, barcode: "4006381333931", wantErr: true},
+		{format: GTIN14, barcode: "1001234567890A", wantErr: true},
+		{format: "GS1", barcode: "12345", wantErr: true},
+		{format: Code128, barcode: "", wantErr: true},
+		{format: Code128, barcode: "TC\t1", wantErr: true},
+		{format: Code128, barcode: "TC20512-é", wantErr: true},
+		{format: "QR", barcode: "4006381333931", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.barcode, func(t *testing.T) {
+			if err := ValidateBarcode(tt.format, tt.barcode); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBarcode(%q, %q) error = %v, wantErr %v", tt.format, tt.barcode, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateBarcode(t *testing.T) {
+	previous := TestCode
+	t.Cleanup(func() { TestCode = previous })
+
+	tests := []struct {
+		testCode string
+		format   string
+		sequence int
+		want     string
+		wantErr  bool
+	}{
+		{testCode: "20512", format: EAN13, sequence: 7, want: "2120512000072"},
+		{testCode: "20512", format: EAN13, sequence: 0, want: "2120512000003"},
+		{testCode: "20512", format: UPCA, sequence: 7, want: "220512000071"},
+		{testCode: "20512", format: GTIN14, sequence: 7, want: "12120512000079"},
+		{testCode: "20512", format: Code128, sequence: 7, want: "TC20512-00007"},
+		{testCode: "20512", format: "ean13", sequence: 7, want: "2120512000072"},
+		{testCode: "5001", format: Code128, sequence: MaxBarcodeSequence, want: "TC05001-99999"},
+		{testCode: "20512", format: EAN13, sequence: -1, wantErr: true},
+		{testCode: "20512", format: EAN13, sequence: MaxBarcodeSequence + 1, wantErr: true},
+		{testCode: "205123", format: EAN13, sequence: 1, wantErr: true},
+		{testCode: "2O512", format: EAN13, sequence: 1, wantErr: true},
+		{testCode: "20512", format: "QR", sequence: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testCode+" "+tt.format, func(t *testing.T) {
+			TestCode = tt.testCode
+			got, err := GenerateBarcode(tt.format, tt.sequence)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateBarcode(%q, %d) error = %v, wantErr %v", tt.format, tt.sequence, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GenerateBarcode(%q, %d) = %q, want %q", tt.format, tt.sequence, got, tt.want)
+			}
+			if err := ValidateBarcode(tt.format, got); err != nil {
+				t.Errorf("generated barcode is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestRandomBarcodesHaveValidCheckDigits(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if barcode := RandomEAN13(); ValidateBarcode(EAN13, barcode) != nil {
+			t.Fatalf("RandomEAN13() = %q has an invalid check digit", barcode)
+		}
+		if barcode := RandomGTIN14(); ValidateBarcode(GTIN14, barcode) != nil {
+			t.Fatalf("RandomGTIN14() = %q has an invalid check digit", barcode)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package data_helpers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock modes
+const (
+	ClockReal   = "real"
+	ClockFixed  = "fixed"
+	ClockOffset = "offset"
+)
+
+// clockLayouts are the accepted forms of a test date or time; a date alone has no time of day
+var clockLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// clockState describes where the framework clock stands relative to the real time
+type clockState struct {
+	mode     string
+	fixed    time.Time
+	offset   time.Duration
+	location *time.Location
+}
+
+// The configured clock is what every scenario starts from; steps may change the current clock until ResetClock.
+var (
+	clockMutex      sync.Mutex
+	configuredClock = clockState{mode: ClockReal, location: time.Local}
+	currentClock    = configuredClock
+)
+
+// Now returns the current time of the framework clock, in the clock's time zone.
+// All dynamic dates and times are derived from it instead of time.Now().
+func Now() time.Time {
+	clockMutex.Lock()
+	state := currentClock
+	clockMutex.Unlock()
+
+	switch state.mode {
+	case ClockFixed:
+		return state.fixed.In(state.location)
+	case ClockOffset:
+		return time.Now().Add(state.offset).In(state.location)
+	}
+	return time.Now().In(state.location)
+}
+
+// ConfigureClock sets the clock every scenario starts from.
+//   - real: the real time
+//   - fixed: frozen at the given date or time; a date alone is frozen at noon, away from midnight
+//   - offset: the real time shifted by the offset (e.g. "72h" or "-30m"), or running from the given date or time
+//
+// The timezone is an IANA name such as "Europe/London"; empty means the local time zone.
+func ConfigureClock(mode, at, offset, timezone string) error {
+	state, err := newClockState(mode, at, offset, timezone)
+	if err != nil {
+		return err
+	}
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+	configuredClock = state
+	currentClock = state
+	return nil
+}
+
+// SetTestDate moves the current clock to a date, keeping the time of day running, or to a date and time.
+// A fixed clock stays fixed at the new date; the configured clock is restored by ResetClock.
+func SetTestDate(at string) error {
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+
+	target, dateOnly, err := parseClockTime(at, currentClock.location)
+	if err != nil {
+		return err
+	}
+	if currentClock.mode == ClockFixed {
+		if dateOnly {
+			target = target.Add(12 * time.Hour)
+		}
+		currentClock.fixed = target
+		return nil
+	}
+	currentClock.mode = ClockOffset
+	currentClock.offset = offsetTo(target, dateOnly, currentClock.location)
+	return nil
+}
+
+// SetClockTimezone changes the time zone of the current clock, e.g. "America/New_York" or "UTC"
+func SetClockTimezone(timezone string) error {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q: %v", timezone, err)
+	}
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+	currentClock.location = location
+	return nil
+}
+
+// ResetClock restores the configured clock after a scenario changed it
+func ResetClock() {
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+	currentClock = configuredClock
+}
+
+// ClockDescription describes the current clock for the report, e.g. "fixed at 2026-12-31T12:00:00Z (UTC)"
+func ClockDescription() string {
+	clockMutex.Lock()
+	state := currentClock
+	clockMutex.Unlock()
+
+	switch state.mode {
+	case ClockFixed:
+		return fmt.Sprintf("fixed at %s (%s)", state.fixed.In(state.location).Format(time.RFC3339), state.location)
+	case ClockOffset:
+		return fmt.Sprintf("offset by %s, now %s (%s)", state.offset.Round(time.Second), Now().Format(time.RFC3339), state.location)
+	}
+	return fmt.Sprintf("real time (%s)", state.location)
+}
+
+// newClockState builds a clock from its configuration
+func newClockState(mode, at, offset, timezone string) (clockState, error) {
+	state := clockState{mode: mode, location: time.Local}
+	if state.mode == "" {
+		state.mode = ClockReal
+	}
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return state, fmt.Errorf("unknown time zone %q: %v", timezone, err)
+		}
+		state.location = location
+	}
+
+	switch state.mode {
+	case ClockReal:
+	case ClockFixed:
+		target, dateOnly, err := parseClockTime(at, state.location)
+		if err != nil {
+			return state, err
+		}
+		if dateOnly {
+			target = target.Add(12 * time.Hour)
+		}
+		state.fixed = target
+	case ClockOffset:
+		if at != "" {
+			target, dateOnly, err := parseClockTime(at, state.location)
+			if err != nil {
+				return state, err
+			}
+			state.offset = offsetTo(target, dateOnly, state.location)
+		}
+		if offset != "" {
+			shift, err := time.ParseDuration(offset)
+			if err != nil {
+				return state, fmt.Errorf("invalid clock offset %q: %v", offset, err)
+			}
+			state.offset += shift
+		}
+	default:
+		return state, fmt.Errorf("unknown clock mode %q (expected %s, %s or %s)", mode, ClockReal, ClockFixed, ClockOffset)
+	}
+	return state, nil
+}
+
+// parseClockTime reads a date (2006-01-02) or a date and time, with or without a UTC offset, in a time zone
+func parseClockTime(value string, location *time.Location) (time.Time, bool, error) {
+	for _, layout := range clockLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, layout == "2006-01-02", nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid test date %q, expected 2006-01-02, 2006-01-02T15:04:05 or RFC 3339", value)
+}
+
+// offsetTo returns the shift from the real time to a target; for a date alone, the time of day is kept
+func offsetTo(target time.Time, dateOnly bool, location *time.Location) time.Duration {
+	now := time.Now().In(location)
+	if dateOnly {
+		target = time.Date(target.Year(), target.Month(), target.Day(), now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), location)
+	}
+	return target.Sub(now)
+}
@@ -0,0 +1,107 @@
+package data_helpers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DataDir is the directory holding the data files that @data(file) tags refer to
+var DataDir = filepath.Join(".", "fixtures", "data")
+
+// DataTable is the content of a data file: column names and one row of values per example
+type DataTable struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// LoadDataFile reads a .csv file (the first record holds the column names) or a .json file holding an array of objects.
+// Relative names are looked up in DataDir.
+func LoadDataFile(name string) (*DataTable, error) {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(DataDir, name)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read data file %q: %v", name, err)
+	}
+
+	var table *DataTable
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		table, err = parseCSVData(data)
+	case ".json":
+		table, err = parseJSONData(data)
+	default:
+		return nil, fmt.Errorf("data file %q must be a .csv or .json file", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid data file %q: %v", name, err)
+	}
+	return table, nil
+}
+
+// parseCSVData reads a header record followed by one record per row
+func parseCSVData(data []byte) (*DataTable, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+	for i, column := range records[0] {
+		records[0][i] = strings.TrimSpace(column)
+	}
+	return &DataTable{Columns: records[0], Rows: records[1:]}, nil
+}
+
+// parseJSONData reads an array of flat objects; the columns are the keys of all objects, sorted by name.
+// Strings are used as they are, other values in their JSON form, and missing keys become empty cells.
+func parseJSONData(data []byte) (*DataTable, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var objects []map[string]interface{}
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("expected an array of objects: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	table := &DataTable{}
+	for _, object := range objects {
+		for key := range object {
+			if !seen[key] {
+				seen[key] = true
+				table.Columns = append(table.Columns, key)
+			}
+		}
+	}
+	sort.Strings(table.Columns)
+
+	for _, object := range objects {
+		row := make([]string, len(table.Columns))
+		for i, column := range table.Columns {
+			switch value := object[column].(type) {
+			case nil:
+			case string:
+				row[i] = value
+			default:
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return nil, err
+				}
+				row[i] = string(encoded)
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
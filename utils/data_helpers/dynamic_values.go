@@ -1,11 +1,9 @@
 package data_helpers
 
 import (
-	"crypto/rand"
 	"fmt"
-	"math/big"
+	"math/rand"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,59 +11,59 @@ import (
 
 // Time-related functions
 
-// Current Date and Time
+// Current Date and Time, from the framework clock (see clock.go)
 func TestDate() string {
-	return time.Now().Format("2006-01-02")
+	return Now().Format("2006-01-02")
 }
 
 func TestTime() string {
-	return time.Now().Format("15:04:05")
+	return Now().Format("15:04:05")
 }
 
 func TestTimestamp() string {
-	return time.Now().Format(time.RFC3339)
+	return Now().Format(time.RFC3339)
 }
 
 // Future Time functions
 func FutureTimeMinutes(minutes int) string {
-	return time.Now().Add(time.Duration(minutes) * time.Minute).Format("15:04:05")
+	return Now().Add(time.Duration(minutes) * time.Minute).Format("15:04:05")
 }
 
 func FutureTimeHours(hours int) string {
-	return time.Now().Add(time.Duration(hours) * time.Hour).Format("15:04:05")
+	return Now().Add(time.Duration(hours) * time.Hour).Format("15:04:05")
 }
 
 func FutureDateDays(days int) string {
-	return time.Now().AddDate(0, 0, days).Format("2006-01-02")
+	return Now().AddDate(0, 0, days).Format("2006-01-02")
 }
 
 // Past Time functions
 func PastTimeMinutes(minutes int) string {
-	return time.Now().Add(-time.Duration(minutes) * time.Minute).Format("15:04:05")
+	return Now().Add(-time.Duration(minutes) * time.Minute).Format("15:04:05")
 }
 
 func PastTimeHours(hours int) string {
-	return time.Now().Add(-time.Duration(hours) * time.Hour).Format("15:04:05")
+	return Now().Add(-time.Duration(hours) * time.Hour).Format("15:04:05")
 }
 
 func PastDateDays(days int) string {
-	return time.Now().AddDate(0, 0, -days).Format("2006-01-02")
+	return Now().AddDate(0, 0, -days).Format("2006-01-02")
 }
 
 // Random value generators
 
-// Generate a random UUID
+// Generate a random version 4 UUID from the seeded generator
 func RandomUUID() string {
-	return uuid.New().String()
+	var id uuid.UUID
+	withGenerator(func(r *rand.Rand) {
+		id, _ = uuid.NewRandomFromReader(r)
+	})
+	return id.String()
 }
 
-// Generate a random 4-digit number
+// Generate a random 4-digit number from the seeded generator
 func Random4DigitNumber() int {
-	n, err := rand.Int(rand.Reader, big.NewInt(9000))
-	if err != nil {
-		panic(err)
-	}
-	return int(n.Int64()) + 1000 // Ensure the number is 4 digits
+	return RandomInt(1000, 9999)
 }
 
 // Test execution variables based on dynamic calculations
@@ -89,14 +87,15 @@ func GenerateTestVariables(todayTestRound int) map[string]int {
 	}
 
 	// Dynamic 4-digit test value derived from current month, date, and test round
-	currentMonth := int(time.Now().Month())
-	currentDay := time.Now().Day()
+	now := Now()
+	currentMonth := int(now.Month())
+	currentDay := now.Day()
 	testMD := currentMonth*31 + currentDay + todayTestRound*1000
 	testVariables["testMD"] = testMD
 	testVariables["testMD500"] = testMD + 500
 
 	// 6-digit number used for IDs in dynamic test data usually
-	testDay, _ := strconv.Atoi(time.Now().Format("060102")) // yyMMdd
+	testDay, _ := strconv.Atoi(now.Format("060102")) // yyMMdd
 	testVariables["testDay"] = testDay
 
 	// 7-digit number used for IDs in dynamic test data
@@ -110,9 +109,10 @@ func GenerateTestVariables(todayTestRound int) map[string]int {
 	return testVariables
 }
 
-// Helper function to get time with a specific time zone offset (e.g., "+01:00")
+// Helper function to get the current time in a zone with a specific UTC offset (e.g., "+01:00")
 func GetTimeWithZoneOffset(offsetHours int) string {
-	return time.Now().UTC().Add(time.Duration(offsetHours) * time.Hour).Format("2006-01-02T15:04:05-07:00")
+	zone := time.FixedZone(fmt.Sprintf("UTC%+d", offsetHours), offsetHours*60*60)
+	return Now().In(zone).Format("2006-01-02T15:04:05-07:00")
 }
 
 // Sample usage of future date and time
@@ -122,34 +122,30 @@ func FutureDateTimeSample() string {
 	return fmt.Sprintf("%sT%s", futureDate, futureTime)
 }
 
-// GenerateTestCode generates a 4-digit test code based on a string in the format "110-010-001"
+// GenerateTestCode generates the test code of a test ID in the format "110-010-001": the test round of the run
+// followed by the 4-digit code of the test ID, so that reruns on the same day send new product codes and barcodes.
 // tcId = ((categoryNumber / 10 - 1) * 500) + ((methodNumber / 10 - 1) * 50) + testcaseNumber
+// Test IDs outside the naming convention are rejected, see ValidateTestID.
 func GenerateTestCode(testCodeString string) error {
-	// Split the input string by the '-' delimiter
-	parts := strings.Split(testCodeString, "-")
-	if len(parts) != 3 {
-		return fmt.Errorf("invalid test code format: %s", testCodeString)
-	}
-
-	// Convert each part to an integer
-	categoryNumber, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return fmt.Errorf("invalid category number: %s", parts[0])
-	}
-
-	methodNumber, err := strconv.Atoi(parts[1])
+	testCode, err := TestCodeFor(testCodeString)
 	if err != nil {
-		return fmt.Errorf("invalid method number: %s", parts[1])
+		return err
 	}
+	TestCode = fmt.Sprintf("%d%s", TestRound, testCode) // Updates the global TestCode variable
+	scenarioTestCode = true
+	return nil
+}
 
-	testcaseNumber, err := strconv.Atoi(parts[2])
-	if err != nil {
-		return fmt.Errorf("invalid testcase number: %s", parts[2])
-	}
+// scenarioTestCode is set once the current scenario has given itself a TestCode with GenerateTestCode
+var scenarioTestCode bool
 
-	// Calculate the test code as before
-	tcId := ((categoryNumber/10 - 1) * 500) + ((methodNumber/10 - 1) * 50) + testcaseNumber
-	TestCode = fmt.Sprintf("%04d", tcId) // Ensures the TestCode is always a 4-digit number, and updates the global TestCode variable
+// ForgetScenarioTestCode marks the start of a scenario that has not set its own TestCode yet
+func ForgetScenarioTestCode() {
+	scenarioTestCode = false
+}
 
-	return nil
+// ScenarioHasTestCode reports whether the current scenario set its own TestCode. Until it does, TestCode holds
+// the default or the code of the previous scenario, so data derived from it does not belong to this scenario.
+func ScenarioHasTestCode() bool {
+	return scenarioTestCode
 }
@@ -0,0 +1,172 @@
+package data_helpers
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"test-in-go/config"
+	responsehelpers "test-in-go/utils/response_helpers"
+	"time"
+)
+
+// expressionPattern matches a ${...} expression; $${ is an escaped, literal ${
+var expressionPattern = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+// datePattern matches date expressions such as today, today+3d, now-2h or today+1M:dd/MM/yyyy
+var datePattern = regexp.MustCompile(`^(today|now)((?:[+-]\d+[smhdwMy])*)(?::(.+))?$`)
+
+// dateShiftPattern matches one shift of a date expression, e.g. +3d
+var dateShiftPattern = regexp.MustCompile(`([+-])(\d+)([smhdwMy])`)
+
+// dateFormat converts the usual date format letters (yyyy-MM-dd HH:mm:ss) into a Go layout
+var dateFormat = strings.NewReplacer(
+	"yyyy", "2006", "yy", "06", "MMMM", "January", "MMM", "Jan", "MM", "01",
+	"dd", "02", "EEEE", "Monday", "EEE", "Mon", "HH", "15", "hh", "03", "mm", "04", "ss", "05",
+	"SSS", "000", "XXX", "Z07:00",
+)
+
+// Scenario variables, set by steps and read by ${var:name}
+var (
+	variablesMutex sync.Mutex
+	variables      = make(map[string]string)
+)
+
+// SetVariable stores a scenario variable
+func SetVariable(name, value string) {
+	variablesMutex.Lock()
+	defer variablesMutex.Unlock()
+	variables[name] = value
+}
+
+// Variable returns a scenario variable
+func Variable(name string) (string, error) {
+	variablesMutex.Lock()
+	defer variablesMutex.Unlock()
+	value, ok := variables[name]
+	if !ok {
+		return "", fmt.Errorf("scenario variable %q is not set", name)
+	}
+	return value, nil
+}
+
+// ResetVariables forgets the variables of the previous scenario
+func ResetVariables() {
+	variablesMutex.Lock()
+	defer variablesMutex.Unlock()
+	variables = make(map[string]string)
+}
+
+// ExpandExpressions replaces every ${...} expression in text with its value:
+//   - ${testCode}, ${uuid}, ${testDate}, ${testDayN}, ...: the dynamic values also available as {{placeholders}}
+//   - ${today}, ${now}, ${today+3d}, ${now-2h:HH:mm}, ${today+1M:yyyy-MM-dd}: dates from the framework clock,
+//     shifted by s, m, h, d, w, M (months) or y and formatted with yyyy, MM, dd, HH, mm, ss, ... or a Go layout
+//   - ${var:orderId}, or ${orderId} when no dynamic value has that name: a scenario variable
+//   - ${env:SITE}: an environment variable
+//   - ${response.$.id}, ${response.status}, ${response.header.Location}: the last API response
+//
+// Unknown or unavailable values are errors, and so are secrets, because the expanded text is printed in the report:
+// values equal to a registered secret and references such as ${env:API_PASS} that the configuration reads a secret from.
+// $${ keeps a literal ${.
+func ExpandExpressions(text string) (string, error) {
+	var expandErr error
+	expanded := expressionPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		expression := expressionPattern.FindStringSubmatch(match)[1]
+		value, err := EvaluateExpression(expression)
+		if err == nil && (config.IsSecret(value) || config.IsSecretReference(expression)) {
+			// The expanded step text is printed by the report, so a secret would be shown in plain text
+			value, err = "", fmt.Errorf("the value is a secret, which would be printed with the step; the framework sends the credentials itself")
+		}
+		if err != nil && expandErr == nil {
+			expandErr = fmt.Errorf("%s: %v", match, err)
+		}
+		return value
+	})
+	if expandErr != nil {
+		return text, expandErr
+	}
+	return expanded, nil
+}
+
+// EvaluateExpression returns the value of the inside of one ${...} expression
+func EvaluateExpression(expression string) (string, error) {
+	expression = strings.TrimSpace(expression)
+	switch {
+	case strings.HasPrefix(expression, "var:"):
+		return Variable(strings.TrimPrefix(expression, "var:"))
+	case strings.HasPrefix(expression, "env:"):
+		name := strings.TrimPrefix(expression, "env:")
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	case strings.HasPrefix(expression, "response."):
+		response, err := responsehelpers.LastResponse()
+		if err != nil {
+			return "", err
+		}
+		return response.Value(strings.TrimPrefix(expression, "response."))
+	}
+
+	if match := datePattern.FindStringSubmatch(expression); match != nil {
+		return evaluateDate(match[1], match[2], match[3]), nil
+	}
+	if value, ok := placeholderValues[expression]; ok {
+		return value(), nil
+	}
+	if value, ok := TestVariables[expression]; ok {
+		return strconv.Itoa(value), nil
+	}
+	// A bare name that is no dynamic value is a scenario variable, e.g. ${productCode} after I store "$.productCode" as "productCode"
+	if value, err := Variable(expression); err == nil {
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown expression %q: not a dynamic value, date, or scenario variable set in this scenario", expression)
+}
+
+// evaluateDate shifts today or now by the given amounts and formats the result
+func evaluateDate(base, shifts, format string) string {
+	date := Now()
+	layout := time.RFC3339
+	if base == "today" {
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+		layout = "2006-01-02"
+	}
+
+	for _, shift := range dateShiftPattern.FindAllStringSubmatch(shifts, -1) {
+		amount, _ := strconv.Atoi(shift[2])
+		if shift[1] == "-" {
+			amount = -amount
+		}
+		switch shift[3] {
+		case "s":
+			date = date.Add(time.Duration(amount) * time.Second)
+		case "m":
+			date = date.Add(time.Duration(amount) * time.Minute)
+		case "h":
+			date = date.Add(time.Duration(amount) * time.Hour)
+		case "d":
+			date = date.AddDate(0, 0, amount)
+		case "w":
+			date = date.AddDate(0, 0, 7*amount)
+		case "M":
+			date = date.AddDate(0, amount, 0)
+		case "y":
+			date = date.AddDate(amount, 0, 0)
+		}
+	}
+
+	if format != "" {
+		layout = format
+		if !strings.Contains(format, "2006") {
+			layout = dateFormat.Replace(format)
+		}
+	}
+	return date.Format(layout)
+}
@@ -0,0 +1,63 @@
+package data_helpers
+
+import (
+	"test-in-go/config"
+	"testing"
+)
+
+func TestExpandExpressions(t *testing.T) {
+	if err := ConfigureClock(ClockFixed, "2026-03-10T09:30:00Z", "", "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ConfigureClock(ClockReal, "", "", "") })
+	ResetVariables()
+	t.Cleanup(ResetVariables)
+	SetVariable("orderId", "ORD-42")
+
+	t.Setenv("EXPRESSION_TEST_SITE", "LDN")
+	t.Setenv("EXPRESSION_TEST_PASS", "expr-s3cret")
+	if _, err := config.ResolveSecrets("${env:EXPRESSION_TEST_PASS}"); err != nil {
+		t.Fatal(err)
+	}
+	config.RegisterSecret("pass")
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "text without expressions", text: "the product is created", want: "the product is created"},
+		{name: "escaped expression", text: "literal $${today}", want: "literal ${today}"},
+		{name: "today", text: "${today}", want: "2026-03-10"},
+		{name: "now", text: "${now}", want: "2026-03-10T09:30:00Z"},
+		{name: "shifted date", text: "${today+3d}", want: "2026-03-13"},
+		{name: "several shifts", text: "${today+1w-1d}", want: "2026-03-16"},
+		{name: "formatted month shift", text: "${today+1M:dd/MM/yyyy}", want: "10/04/2026"},
+		{name: "formatted time", text: "${now-2h:HH:mm}", want: "07:30"},
+		{name: "go layout", text: "${today-1y:2006-01-02}", want: "2025-03-10"},
+		{name: "scenario variable", text: "order ${var:orderId}", want: "order ORD-42"},
+		{name: "bare scenario variable", text: "order ${orderId}", want: "order ORD-42"},
+		{name: "environment variable", text: "site ${env:EXPRESSION_TEST_SITE}", want: "site LDN"},
+		{name: "several expressions", text: "${orderId} on ${today}", want: "ORD-42 on 2026-03-10"},
+		{name: "value containing a secret", text: "${var:document}", want: "passport"},
+		{name: "unknown expression", text: "value ${nothing}", want: "value ${nothing}", wantErr: true},
+		{name: "unset environment variable", text: "${env:EXPRESSION_TEST_MISSING}", want: "${env:EXPRESSION_TEST_MISSING}", wantErr: true},
+		{name: "secret reference", text: "password ${env:EXPRESSION_TEST_PASS}", want: "password ${env:EXPRESSION_TEST_PASS}", wantErr: true},
+		{name: "value equal to a secret", text: "password ${var:password}", want: "password ${var:password}", wantErr: true},
+	}
+	SetVariable("document", "passport")
+	SetVariable("password", "pass")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandExpressions(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandExpressions(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandExpressions(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package data_helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Patterns for one segment of a field path (a JSON field name optionally followed by [index] parts) and its indexes
+var (
+	fieldPathSegment = regexp.MustCompile(`^([^.\[\]]+)((?:\[\d+\])*)$`)
+	fieldPathIndex   = regexp.MustCompile(`\d+`)
+)
+
+// PathSegment is one step of a field path: a field name or a list index
+type PathSegment struct {
+	Field string
+	Index int
+}
+
+// IsIndex reports whether the segment selects a list element
+func (s PathSegment) IsIndex() bool {
+	return s.Field == ""
+}
+
+// ParseFieldPath splits a path of JSON field names such as "products[0].sku[1].skuId" into segments.
+func ParseFieldPath(path string) ([]PathSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty field path")
+	}
+	var segments []PathSegment
+	for _, part := range strings.Split(path, ".") {
+		match := fieldPathSegment.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("invalid field path %q at %q", path, part)
+		}
+		segments = append(segments, PathSegment{Field: match[1]})
+		for _, index := range fieldPathIndex.FindAllString(match[2], -1) {
+			n, _ := strconv.Atoi(index)
+			segments = append(segments, PathSegment{Index: n})
+		}
+	}
+	return segments, nil
+}
+
+// ToDocument converts a payload struct into generic JSON maps and lists, keyed by JSON field name
+func ToDocument(payload interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("payload is not a JSON object: %v", err)
+	}
+	return document, nil
+}
+
+// GetFieldPath returns the value at a field path of a document
+func GetFieldPath(document map[string]interface{}, path string) (interface{}, error) {
+	parent, last, err := walkFieldPath(document, path)
+	if err != nil {
+		return nil, err
+	}
+	return childValue(parent, last, path)
+}
+
+// SetFieldPath replaces the value at a field path; the parent of the field must exist
+func SetFieldPath(document map[string]interface{}, path string, value interface{}) error {
+	parent, last, err := walkFieldPath(document, path)
+	if err != nil {
+		return err
+	}
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		if last.IsIndex() {
+			return fmt.Errorf("field path %q: expected a field name, got index [%d]", path, last.Index)
+		}
+		container[last.Field] = value
+	case []interface{}:
+		if !last.IsIndex() || last.Index >= len(container) {
+			return fmt.Errorf("field path %q: no list element to set", path)
+		}
+		container[last.Index] = value
+	default:
+		return fmt.Errorf("field path %q: cannot set a field on %T", path, parent)
+	}
+	return nil
+}
+
+// DeleteFieldPath removes a field from its object, or an element from its list
+func DeleteFieldPath(document map[string]interface{}, path string) error {
+	parent, last, err := walkFieldPath(document, path)
+	if err != nil {
+		return err
+	}
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		if _, exists := container[last.Field]; last.IsIndex() || !exists {
+			return fmt.Errorf("field path %q: field not found", path)
+		}
+		delete(container, last.Field)
+	case []interface{}:
+		if !last.IsIndex() || last.Index >= len(container) {
+			return fmt.Errorf("field path %q: list element not found", path)
+		}
+		// Lists are stored in their parent, so the shortened list is written back there
+		shortened := append(container[:last.Index:last.Index], container[last.Index+1:]...)
+		listPath := strings.TrimSuffix(path, fmt.Sprintf("[%d]", last.Index))
+		return SetFieldPath(document, listPath, shortened)
+	default:
+		return fmt.Errorf("field path %q: cannot delete a field from %T", path, parent)
+	}
+	return nil
+}
+
+// walkFieldPath returns the container holding the last segment of a path, together with that segment
+func walkFieldPath(document map[string]interface{}, path string) (interface{}, PathSegment, error) {
+	segments, err := ParseFieldPath(path)
+	if err != nil {
+		return nil, PathSegment{}, err
+	}
+	var current interface{} = document
+	for _, segment := range segments[:len(segments)-1] {
+		current, err = childValue(current, segment, path)
+		if err != nil {
+			return nil, PathSegment{}, err
+		}
+	}
+	return current, segments[len(segments)-1], nil
+}
+
+// childValue selects a field of an object or an element of a list
+func childValue(container interface{}, segment PathSegment, path string) (interface{}, error) {
+	if segment.IsIndex() {
+		list, ok := container.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field path %q: expected a list before [%d]", path, segment.Index)
+		}
+		if segment.Index >= len(list) {
+			return nil, fmt.Errorf("field path %q: index %d out of range (length %d)", path, segment.Index, len(list))
+		}
+		return list[segment.Index], nil
+	}
+	object, ok := container.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("field path %q: expected an object before %q", path, segment.Field)
+	}
+	value, exists := object[segment.Field]
+	if !exists {
+		return nil, fmt.Errorf("field path %q: field %q not found", path, segment.Field)
+	}
+	return value, nil
+}
+
+// elementDefaults create the element appended when a field path indexes one past the end of a list,
+// so e.g. "sku[0].skuId" starts from a complete default SKU rather than an empty one.
+var elementDefaults = map[reflect.Type]func() interface{}{
+	reflect.TypeOf(SKU{}):    func() interface{} { return GenerateSKU() },
+	reflect.TypeOf(SKUUom{}): func() interface{} { return defaultSKUUom() },
+}
+
+// SetStructField sets the field at a JSON field path of a struct, e.g. "sku[0].retailPrice.centsValue",
+// converting the text value to the field's type. Fields are matched by their json tag.
+// Indexing one past the end of a list appends a default element; placeholders such as {{testCode}} are resolved.
+func SetStructField(target interface{}, path, value string) error {
+	segments, err := ParseFieldPath(path)
+	if err != nil {
+		return err
+	}
+	current := reflect.ValueOf(target)
+	if current.Kind() != reflect.Ptr || current.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a pointer to a struct, got %T", target)
+	}
+	current = current.Elem()
+
+	for _, segment := range segments {
+		if segment.IsIndex() {
+			if current.Kind() != reflect.Slice {
+				return fmt.Errorf("field path %q: [%d] applied to a %s", path, segment.Index, current.Kind())
+			}
+			if segment.Index > current.Len() {
+				return fmt.Errorf("field path %q: index %d skips elements (length %d)", path, segment.Index, current.Len())
+			}
+			if segment.Index == current.Len() {
+				current.Set(reflect.Append(current, newElement(current.Type().Elem())))
+			}
+			current = current.Index(segment.Index)
+			continue
+		}
+
+		if current.Kind() != reflect.Struct {
+			return fmt.Errorf("field path %q: field %q applied to a %s", path, segment.Field, current.Kind())
+		}
+		field, ok := structFieldByJSONName(current, segment.Field)
+		if !ok {
+			return fmt.Errorf("field path %q: %s has no field %q", path, current.Type().Name(), segment.Field)
+		}
+		current = field
+	}
+	return setTextValue(current, ResolvePlaceholders(value), path)
+}
+
+// newElement creates the default element for a list of the given type
+func newElement(elemType reflect.Type) reflect.Value {
+	if create, ok := elementDefaults[elemType]; ok {
+		return reflect.ValueOf(create())
+	}
+	return reflect.New(elemType).Elem()
+}
+
+// structFieldByJSONName finds a struct field by the name in its json tag
+func structFieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		tag := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		if tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setTextValue converts text to the kind of a field and assigns it
+func setTextValue(field reflect.Value, value, path string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("field path %q: %q is not an integer", path, value)
+		}
+		field.SetInt(n)
+	case reflect.Float64, reflect.Float32:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("field path %q: %q is not a number", path, value)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("field path %q: %q is not a boolean", path, value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("field path %q: cannot set a %s from text; address one of its fields instead", path, field.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package data_helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    []PathSegment
+		wantErr bool
+	}{
+		{name: "single field", path: "productCode", want: []PathSegment{{Field: "productCode"}}},
+		{name: "nested fields", path: "retailPrice.centsValue", want: []PathSegment{{Field: "retailPrice"}, {Field: "centsValue"}}},
+		{
+			name: "indexes",
+			path: "products[0].sku[12].skuId",
+			want: []PathSegment{{Field: "products"}, {Index: 0}, {Field: "sku"}, {Index: 12}, {Field: "skuId"}},
+		},
+		{name: "nested lists", path: "grid[1][2]", want: []PathSegment{{Field: "grid"}, {Index: 1}, {Index: 2}}},
+		{name: "empty path", path: "", wantErr: true},
+		{name: "empty segment", path: "sku..skuId", wantErr: true},
+		{name: "leading index", path: "[0].skuId", wantErr: true},
+		{name: "negative index", path: "sku[-1]", wantErr: true},
+		{name: "unclosed index", path: "sku[0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFieldPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFieldPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFieldPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// fieldPathLine and fieldPathOrder are small payloads for the SetStructField tests
+type fieldPathLine struct {
+	Code     string `json:"code"`
+	Quantity int    `json:"quantity"`
+}
+
+type fieldPathOrder struct {
+	Reference string          `json:"reference,omitempty"`
+	Weight    float64         `json:"weight"`
+	Urgent    bool            `json:"urgent"`
+	Lines     []fieldPathLine `json:"lines"`
+	Untagged  string
+}
+
+func TestSetStructField(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		value   string
+		check   func(order fieldPathOrder) bool
+		wantErr bool
+	}{
+		{
+			name: "string field by json tag", path: "reference", value: "ORD-1",
+			check: func(o fieldPathOrder) bool { return o.Reference == "ORD-1" },
+		},
+		{
+			name: "float field", path: "weight", value: "2.5",
+			check: func(o fieldPathOrder) bool { return o.Weight == 2.5 },
+		},
+		{
+			name: "bool field", path: "urgent", value: "true",
+			check: func(o fieldPathOrder) bool { return o.Urgent },
+		},
+		{
+			name: "existing list element", path: "lines[0].quantity", value: "7",
+			check: func(o fieldPathOrder) bool {
+				return len(o.Lines) == 1 && o.Lines[0].Quantity == 7 && o.Lines[0].Code == "A"
+			},
+		},
+		{
+			name: "one past the end appends an element", path: "lines[1].code", value: "B",
+			check: func(o fieldPathOrder) bool { return len(o.Lines) == 2 && o.Lines[1].Code == "B" },
+		},
+		{name: "index skipping elements", path: "lines[2].code", value: "C", wantErr: true},
+		{name: "unknown field", path: "customer", value: "x", wantErr: true},
+		{name: "field without json tag", path: "Untagged", value: "x", wantErr: true},
+		{name: "not an integer", path: "lines[0].quantity", value: "seven", wantErr: true},
+		{name: "not a boolean", path: "urgent", value: "maybe", wantErr: true},
+		{name: "index on a scalar", path: "reference[0]", value: "x", wantErr: true},
+		{name: "field on a scalar", path: "weight.grams", value: "1", wantErr: true},
+		{name: "whole list from text", path: "lines", value: "x", wantErr: true},
+		{name: "invalid path", path: "lines[", value: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := fieldPathOrder{Lines: []fieldPathLine{{Code: "A", Quantity: 1}}}
+			err := SetStructField(&order, tt.path, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetStructField(%q, %q) error = %v, wantErr %v", tt.path, tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.check(order) {
+				t.Errorf("SetStructField(%q, %q) gave %+v", tt.path, tt.value, order)
+			}
+		})
+	}
+}
+
+func TestSetStructFieldRequiresStructPointer(t *testing.T) {
+	order := fieldPathOrder{}
+	for _, target := range []interface{}{order, &order.Reference, nil} {
+		if err := SetStructField(target, "reference", "x"); err == nil {
+			t.Errorf("SetStructField(%T) succeeded, want an error", target)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package data_helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InvalidEnumValue is the value WithInvalidEnum puts in enumerated fields
+const InvalidEnumValue = "INVALID_ENUM_VALUE"
+
+// NegativePayload starts from a valid payload and breaks it on purpose, for validation-error scenarios.
+// Fields are addressed by JSON field paths such as "products[0].sku[0].skuId".
+// The first error (e.g. a path that does not exist) is kept and returned by Build.
+type NegativePayload struct {
+	document map[string]interface{}
+	err      error
+}
+
+// NewNegativePayload copies a valid payload, such as a Root, into a document that can be broken.
+func NewNegativePayload(valid interface{}) *NegativePayload {
+	document, err := ToDocument(valid)
+	return &NegativePayload{document: document, err: err}
+}
+
+// WithoutField removes a field, e.g. a mandatory one, or a list element.
+func (p *NegativePayload) WithoutField(path string) *NegativePayload {
+	return p.apply(func() error {
+		return DeleteFieldPath(p.document, path)
+	})
+}
+
+// WithNullField sets a field to null.
+func (p *NegativePayload) WithNullField(path string) *NegativePayload {
+	return p.WithValue(path, nil)
+}
+
+// WithValue sets a field to any value, including values of the wrong type.
+func (p *NegativePayload) WithValue(path string, value interface{}) *NegativePayload {
+	return p.apply(func() error {
+		return SetFieldPath(p.document, path, value)
+	})
+}
+
+// WithWrongType replaces a field with a value of a different JSON type:
+// strings become numbers, numbers and booleans become strings, lists become objects and objects become strings.
+func (p *NegativePayload) WithWrongType(path string) *NegativePayload {
+	return p.apply(func() error {
+		current, err := GetFieldPath(p.document, path)
+		if err != nil {
+			return err
+		}
+		var wrong interface{}
+		switch current.(type) {
+		case string:
+			wrong = 12345
+		case float64:
+			wrong = "not-a-number"
+		case bool:
+			wrong = "not-a-boolean"
+		case []interface{}:
+			wrong = map[string]interface{}{}
+		case map[string]interface{}:
+			wrong = "not-an-object"
+		default:
+			wrong = []interface{}{}
+		}
+		return SetFieldPath(p.document, path, wrong)
+	})
+}
+
+// WithOversizeString sets a string field to a value of the given length.
+func (p *NegativePayload) WithOversizeString(path string, length int) *NegativePayload {
+	return p.apply(func() error {
+		if length < 1 {
+			return fmt.Errorf("oversize string length must be positive, got %d", length)
+		}
+		return SetFieldPath(p.document, path, strings.Repeat("X", length))
+	})
+}
+
+// WithInvalidEnum sets an enumerated field, such as productClass or barcodeType, to a value outside its enumeration.
+func (p *NegativePayload) WithInvalidEnum(path string) *NegativePayload {
+	return p.WithValue(path, InvalidEnumValue)
+}
+
+// Build returns the broken payload, ready to be sent with protocol_helpers.PostRequest.
+func (p *NegativePayload) Build() (map[string]interface{}, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.document, nil
+}
+
+// apply runs a change unless an earlier one failed
+func (p *NegativePayload) apply(change func() error) *NegativePayload {
+	if p.err == nil {
+		p.err = change()
+	}
+	return p
+}
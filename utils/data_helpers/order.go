@@ -0,0 +1,30 @@
+// Code generated by go run main.go generate -schema ./contracts/appserver.yaml -type Order; DO NOT EDIT.
+
+package data_helpers
+
+// Order is an order placed for one or more products.
+type Order struct {
+	OrderId         string      `json:"orderId"`
+	OrderDate       string      `json:"orderDate"`
+	Lines           []OrderLine `json:"lines"`
+	CustomerId      string      `json:"customerId"`
+	DeliveryAddress Address     `json:"deliveryAddress"`
+	Status          string      `json:"status"`
+}
+
+// OrderLine is one product line of an order.
+type OrderLine struct {
+	LineNumber  int         `json:"lineNumber"`
+	ProductCode string      `json:"productCode"`
+	Quantity    int         `json:"quantity"`
+	Barcodes    []Barcode   `json:"barcodes"`
+	UnitPrice   RetailPrice `json:"unitPrice"`
+}
+
+// Address is a delivery address.
+type Address struct {
+	City        string `json:"city"`
+	CountryCode string `json:"countryCode"`
+	Line1       string `json:"line1"`
+	Postcode    string `json:"postcode"`
+}
@@ -0,0 +1,150 @@
+// Code generated by go run main.go generate -schema ./contracts/appserver.yaml -type Order; DO NOT EDIT.
+
+package data_helpers
+
+// OrderBuilder builds Order payloads starting from the schema defaults.
+type OrderBuilder struct {
+	order Order
+}
+
+func NewOrderBuilder() *OrderBuilder {
+	return &OrderBuilder{
+		order: Order{
+			OrderId:         ResolvePlaceholders("ORD-{{testCode}}"),
+			OrderDate:       ResolvePlaceholders("{{testDate}}"),
+			Lines:           []OrderLine{},
+			CustomerId:      ResolvePlaceholders("CUST-{{testCode}}"),
+			DeliveryAddress: NewAddressBuilder().Build(),
+			Status:          "PLACED",
+		},
+	}
+}
+
+func (b *OrderBuilder) WithOrderId(orderId string) *OrderBuilder {
+	b.order.OrderId = orderId
+	return b
+}
+
+func (b *OrderBuilder) WithOrderDate(orderDate string) *OrderBuilder {
+	b.order.OrderDate = orderDate
+	return b
+}
+
+func (b *OrderBuilder) WithLines(lines []OrderLine) *OrderBuilder {
+	b.order.Lines = lines
+	return b
+}
+
+func (b *OrderBuilder) AddLine(item OrderLine) *OrderBuilder {
+	b.order.Lines = append(b.order.Lines, item)
+	return b
+}
+
+func (b *OrderBuilder) WithCustomerId(customerId string) *OrderBuilder {
+	b.order.CustomerId = customerId
+	return b
+}
+
+func (b *OrderBuilder) WithDeliveryAddress(deliveryAddress Address) *OrderBuilder {
+	b.order.DeliveryAddress = deliveryAddress
+	return b
+}
+
+func (b *OrderBuilder) WithStatus(status string) *OrderBuilder {
+	b.order.Status = status
+	return b
+}
+
+func (b *OrderBuilder) Build() Order {
+	return b.order
+}
+
+// OrderLineBuilder builds OrderLine payloads starting from the schema defaults.
+type OrderLineBuilder struct {
+	orderLine OrderLine
+}
+
+func NewOrderLineBuilder() *OrderLineBuilder {
+	return &OrderLineBuilder{
+		orderLine: OrderLine{
+			LineNumber:  1,
+			ProductCode: ResolvePlaceholders("PRD-{{testCode}}"),
+			Quantity:    1,
+			Barcodes:    []Barcode{},
+		},
+	}
+}
+
+func (b *OrderLineBuilder) WithLineNumber(lineNumber int) *OrderLineBuilder {
+	b.orderLine.LineNumber = lineNumber
+	return b
+}
+
+func (b *OrderLineBuilder) WithProductCode(productCode string) *OrderLineBuilder {
+	b.orderLine.ProductCode = productCode
+	return b
+}
+
+func (b *OrderLineBuilder) WithQuantity(quantity int) *OrderLineBuilder {
+	b.orderLine.Quantity = quantity
+	return b
+}
+
+func (b *OrderLineBuilder) WithBarcodes(barcodes []Barcode) *OrderLineBuilder {
+	b.orderLine.Barcodes = barcodes
+	return b
+}
+
+func (b *OrderLineBuilder) AddBarcode(item Barcode) *OrderLineBuilder {
+	b.orderLine.Barcodes = append(b.orderLine.Barcodes, item)
+	return b
+}
+
+func (b *OrderLineBuilder) WithUnitPrice(unitPrice RetailPrice) *OrderLineBuilder {
+	b.orderLine.UnitPrice = unitPrice
+	return b
+}
+
+func (b *OrderLineBuilder) Build() OrderLine {
+	return b.orderLine
+}
+
+// AddressBuilder builds Address payloads starting from the schema defaults.
+type AddressBuilder struct {
+	address Address
+}
+
+func NewAddressBuilder() *AddressBuilder {
+	return &AddressBuilder{
+		address: Address{
+			City:        "London",
+			CountryCode: "GBR",
+			Line1:       "1 Test Street",
+			Postcode:    "SW1A 1AA",
+		},
+	}
+}
+
+func (b *AddressBuilder) WithCity(city string) *AddressBuilder {
+	b.address.City = city
+	return b
+}
+
+func (b *AddressBuilder) WithCountryCode(countryCode string) *AddressBuilder {
+	b.address.CountryCode = countryCode
+	return b
+}
+
+func (b *AddressBuilder) WithLine1(line1 string) *AddressBuilder {
+	b.address.Line1 = line1
+	return b
+}
+
+func (b *AddressBuilder) WithPostcode(postcode string) *AddressBuilder {
+	b.address.Postcode = postcode
+	return b
+}
+
+func (b *AddressBuilder) Build() Address {
+	return b.address
+}
@@ -0,0 +1,60 @@
+package data_helpers
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// placeholderPattern matches dynamic placeholders such as {{testCode}} in feature file values
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// placeholderValues maps placeholder names to the dynamic values they are replaced with
+var placeholderValues = map[string]func() string{
+	"testCode":       func() string { return TestCode },
+	"productBarcode": ProductBarcode,
+	"testDate":       TestDate,
+	"testTime":       TestTime,
+	"testTimestamp":  TestTimestamp,
+	"uuid":           RandomUUID,
+	"random4Digit":   func() string { return strconv.Itoa(Random4DigitNumber()) },
+	"fullName":       RandomFullName,
+	"ean13":          RandomEAN13,
+	"gtin14":         RandomGTIN14,
+}
+
+// ResolvePlaceholders replaces every known {{name}} placeholder in text with its dynamic value.
+// The test variables of the run, such as {{todayTestRound}} or {{testDayN}}, are placeholders too.
+// Unknown placeholders are left untouched so that they show up in failure messages.
+func ResolvePlaceholders(text string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := placeholderValues[name]; ok {
+			return value()
+		}
+		if value, ok := TestVariables[name]; ok {
+			return strconv.Itoa(value)
+		}
+		return match
+	})
+}
+
+// scenarioPlaceholders only get their value once a scenario runs, e.g. {{testCode}} from "a new testcase with ID"
+var scenarioPlaceholders = map[string]bool{
+	"testCode":       true,
+	"productBarcode": true,
+}
+
+// ResolveRowPlaceholders resolves the placeholders of one data file row when the examples are generated,
+// so that every row gets its own {{uuid}} or {{random4Digit}}. Scenario placeholders are kept for the steps to resolve.
+func ResolveRowPlaceholders(text string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := placeholderValues[name]; ok && !scenarioPlaceholders[name] {
+			return value()
+		}
+		if value, ok := TestVariables[name]; ok {
+			return strconv.Itoa(value)
+		}
+		return match
+	})
+}
@@ -1,5 +1,7 @@
 package data_helpers
 
+import "test-in-go/utils/logging_helpers"
+
 type ProductBuilder struct {
 	product Product
 }
@@ -19,7 +21,7 @@ func NewProductBuilder() *ProductBuilder {
 			PutawayCodeCheckRequired: false,
 			BarcodeScanRequired:      true,
 			Barcodes: []Barcode{
-				{Barcode: "421" + TestCode, BarcodeType: "EACH"},
+				{Barcode: ProductBarcode(), BarcodeType: "EACH"},
 			},
 			Sellable:         DefaultSellable,
 			Secure:           DefaultSecure,
@@ -68,7 +70,9 @@ func (b *ProductBuilder) WithSKU(sku SKU) *ProductBuilder {
 func (b *ProductBuilder) WithTestCode() *ProductBuilder {
 	b.product.ProductCode = "PRD-" + TestCode
 	b.product.ShortDescription = "desc" + TestCode
-	b.product.Barcodes[0].Barcode = "421" + TestCode
+	if len(b.product.Barcodes) > 0 {
+		b.product.Barcodes[0].Barcode = ProductBarcode()
+	}
 	return b
 }
 
@@ -77,45 +81,204 @@ func (b *ProductBuilder) WithShortDescription(description string) *ProductBuilde
 	return b
 }
 
+func (b *ProductBuilder) WithProductCode(productCode string) *ProductBuilder {
+	b.product.ProductCode = productCode
+	return b
+}
+
+func (b *ProductBuilder) WithImageUrl(imageUrl string) *ProductBuilder {
+	b.product.ImageUrl = imageUrl
+	return b
+}
+
+func (b *ProductBuilder) WithProductClass(productClass string) *ProductBuilder {
+	b.product.ProductClass = productClass
+	return b
+}
+
+func (b *ProductBuilder) WithProductHierarchyId(productHierarchyId string) *ProductBuilder {
+	b.product.ProductHierarchyId = productHierarchyId
+	return b
+}
+
+func (b *ProductBuilder) WithTemperatureClass(temperatureClass string) *ProductBuilder {
+	b.product.TemperatureClass = temperatureClass
+	return b
+}
+
+func (b *ProductBuilder) WithStorageArea(storageArea string) *ProductBuilder {
+	b.product.StorageArea = storageArea
+	return b
+}
+
+// WithBarcode adds a barcode to the default one; use WithoutBarcodes first to replace it.
+func (b *ProductBuilder) WithBarcode(barcode, barcodeType string) *ProductBuilder {
+	b.product.Barcodes = append(b.product.Barcodes, Barcode{Barcode: barcode, BarcodeType: barcodeType})
+	return b
+}
+
+func (b *ProductBuilder) WithBarcodes(barcodes []Barcode) *ProductBuilder {
+	b.product.Barcodes = barcodes
+	return b
+}
+
+func (b *ProductBuilder) WithoutBarcodes() *ProductBuilder {
+	b.product.Barcodes = []Barcode{}
+	return b
+}
+
+func (b *ProductBuilder) WithSKUs(skus []SKU) *ProductBuilder {
+	b.product.SKU = skus
+	return b
+}
+
+func (b *ProductBuilder) WithoutSKUs() *ProductBuilder {
+	b.product.SKU = []SKU{}
+	return b
+}
+
+func (b *ProductBuilder) WithPickingCodeCheckRequired(required bool) *ProductBuilder {
+	b.product.PickingCodeCheckRequired = required
+	return b
+}
+
+func (b *ProductBuilder) WithPutawayCodeCheckRequired(required bool) *ProductBuilder {
+	b.product.PutawayCodeCheckRequired = required
+	return b
+}
+
+func (b *ProductBuilder) WithBarcodeScanRequired(required bool) *ProductBuilder {
+	b.product.BarcodeScanRequired = required
+	return b
+}
+
+func (b *ProductBuilder) WithSellable(sellable bool) *ProductBuilder {
+	b.product.Sellable = sellable
+	return b
+}
+
+func (b *ProductBuilder) WithAgeRestriction(age int) *ProductBuilder {
+	b.product.AgeRestriction = age
+	return b
+}
+
+func (b *ProductBuilder) WithCanTaint(canTaint bool) *ProductBuilder {
+	b.product.CanTaint = canTaint
+	return b
+}
+
+func (b *ProductBuilder) WithCanBeTainted(canBeTainted bool) *ProductBuilder {
+	b.product.CanBeTainted = canBeTainted
+	return b
+}
+
+func (b *ProductBuilder) WithHazardous(hazardous string) *ProductBuilder {
+	b.product.Hazardous = hazardous
+	return b
+}
+
+func (b *ProductBuilder) WithRestricted(restricted string) *ProductBuilder {
+	b.product.Restricted = restricted
+	return b
+}
+
+func (b *ProductBuilder) WithFamilyGroup(familyGroup string) *ProductBuilder {
+	b.product.FamilyGroup = familyGroup
+	return b
+}
+
+func (b *ProductBuilder) WithSecure(secure bool) *ProductBuilder {
+	b.product.Secure = secure
+	return b
+}
+
+func (b *ProductBuilder) WithCatchweight(catchweight bool) *ProductBuilder {
+	b.product.Catchweight = catchweight
+	return b
+}
+
+func (b *ProductBuilder) WithLoose(loose bool) *ProductBuilder {
+	b.product.Loose = loose
+	return b
+}
+
+func (b *ProductBuilder) WithPrePick(prePick bool) *ProductBuilder {
+	b.product.PrePick = prePick
+	return b
+}
+
+func (b *ProductBuilder) WithInStoreBakery(inStoreBakery bool) *ProductBuilder {
+	b.product.InStoreBakery = inStoreBakery
+	return b
+}
+
+func (b *ProductBuilder) WithSecurityTagged(securityTagged bool) *ProductBuilder {
+	b.product.SecurityTagged = securityTagged
+	return b
+}
+
+func (b *ProductBuilder) WithGoodsNotReady(goodsNotReady bool) *ProductBuilder {
+	b.product.GoodsNotReady = goodsNotReady
+	return b
+}
+
+func (b *ProductBuilder) WithMadeToOrder(madeToOrder bool) *ProductBuilder {
+	b.product.MadeToOrder = madeToOrder
+	return b
+}
+
+func (b *ProductBuilder) WithCounter(counter bool) *ProductBuilder {
+	b.product.Counter = counter
+	return b
+}
+
+func (b *ProductBuilder) WithOrganic(organic bool) *ProductBuilder {
+	b.product.Organic = organic
+	return b
+}
+
+func (b *ProductBuilder) WithVirtualStock(virtualStock bool) *ProductBuilder {
+	b.product.VirtualStock = virtualStock
+	return b
+}
+
+func (b *ProductBuilder) WithAlwaysBag(alwaysBag bool) *ProductBuilder {
+	b.product.AlwaysBag = alwaysBag
+	return b
+}
+
+func (b *ProductBuilder) WithSubstitution(from, to, mode string) *ProductBuilder {
+	b.product.SubstitutionFrom = from
+	b.product.SubstitutionTo = to
+	b.product.SubstitutionMode = mode
+	return b
+}
+
+// SetField sets any product field by its JSON field path, e.g. SetField("sku[0].retailPrice.centsValue", "250").
+func (b *ProductBuilder) SetField(path, value string) error {
+	return SetStructField(&b.product, path, value)
+}
+
 func (b *ProductBuilder) Build() Product {
 	return b.product
 }
 
+// BuildRoot wraps the product in the Root structure sent to the product API.
+func (b *ProductBuilder) BuildRoot() Root {
+	return Root{Products: []Product{b.product}}
+}
+
+// BuildInvalid starts a negative payload from the product wrapped in its Root,
+// so field paths start with "products[0].", e.g. "products[0].productCode".
+func (b *ProductBuilder) BuildInvalid() *NegativePayload {
+	return NewNegativePayload(b.BuildRoot())
+}
+
 func GenerateSKU() SKU {
 	return SKU{
-		SKUId:       "SKU-" + TestCode,
-		Description: "Product SKU",
-		SKUUom: []SKUUom{
-			{
-				UnitOfMeasure: "EACH",
-				Height: ScalarUnit{
-					Scalar: 50,
-					Units:  "MM",
-				},
-				Width: ScalarUnit{
-					Scalar: 60,
-					Units:  "MM",
-				},
-				Depth: ScalarUnit{
-					Scalar: 70,
-					Units:  "MM",
-				},
-				Volume: ScalarUnit{
-					Scalar: 210,
-					Units:  "CC",
-				},
-				Weight: ScalarUnit{
-					Scalar: 100,
-					Units:  "G",
-				},
-				UnitsPerParent: []UnitsPerParent{
-					{
-						UnitOfMeasure: "EACH",
-						NoOfUnits:     23,
-					},
-				},
-			},
-		},
+		SKUId:                 "SKU-" + TestCode,
+		Description:           "Product SKU",
+		SKUUom:                []SKUUom{defaultSKUUom()},
 		SupplierID:            "Gelato Inc",
 		SupplierReference:     "stracciatella 500ml",
 		MinimumLifeOnReceipt:  8,
@@ -126,7 +289,7 @@ func GenerateSKU() SKU {
 		},
 		CountryOfOrigin: "GBR",
 		SKUBarcodes: []Barcode{
-			{Barcode: "SKU" + TestCode, BarcodeType: "EACH"},
+			{Barcode: skuBarcode(), BarcodeType: "EACH"},
 		},
 	}
 }
@@ -170,3 +333,48 @@ func GenerateProductsRoot() Root {
 		Products: []Product{product1, product2},
 	}
 }
+
+// defaultSKUUom is the unit of measure of a default SKU
+func defaultSKUUom() SKUUom {
+	return SKUUom{
+		UnitOfMeasure: "EACH",
+		Height: ScalarUnit{
+			Scalar: 50,
+			Units:  "MM",
+		},
+		Width: ScalarUnit{
+			Scalar: 60,
+			Units:  "MM",
+		},
+		Depth: ScalarUnit{
+			Scalar: 70,
+			Units:  "MM",
+		},
+		Volume: ScalarUnit{
+			Scalar: 210,
+			Units:  "CC",
+		},
+		Weight: ScalarUnit{
+			Scalar: 100,
+			Units:  "G",
+		},
+		UnitsPerParent: []UnitsPerParent{
+			{
+				UnitOfMeasure: "EACH",
+				NoOfUnits:     23,
+			},
+		},
+	}
+}
+
+// skuBarcode returns the next unused EAN-13 of the current TestCode for a default SKU.
+// Without a valid TestCode or with the sequence used up there is no collision-free barcode,
+// so the error is logged and the barcode left empty for the API to reject.
+func skuBarcode() string {
+	barcode, err := NextBarcode(EAN13)
+	if err != nil {
+		logging_helpers.Logger().Warn("Could not generate a SKU barcode: ", err)
+		return ""
+	}
+	return barcode
+}
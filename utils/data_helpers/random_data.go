@@ -0,0 +1,134 @@
+package data_helpers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The seeded generator behind every random test value. The same seed produces the same values in the same order,
+// so a failing run can be reproduced with --seed.
+var (
+	randomSeed  = time.Now().UnixNano()
+	randomMutex sync.Mutex
+	generator   = rand.New(rand.NewSource(randomSeed))
+)
+
+// Sample data the generators choose from
+var (
+	firstNames  = []string{"Alice", "Ben", "Chloe", "Daniel", "Emma", "Farid", "Grace", "Hiro", "Isla", "Jack", "Leila", "Noah", "Olivia", "Priya", "Sam", "Zoe"}
+	lastNames   = []string{"Anderson", "Brown", "Clarke", "Davies", "Evans", "Fernando", "Green", "Hughes", "Khan", "Lewis", "Morgan", "Patel", "Smith", "Taylor", "Walker", "Wilson"}
+	streetNames = []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Park Avenue", "Mill Lane", "Queens Road", "Green Lane"}
+	cities      = []string{"London", "Manchester", "Birmingham", "Leeds", "Glasgow", "Bristol", "Cardiff", "Edinburgh"}
+)
+
+// SetSeed restarts the generator from a seed, e.g. the one printed in the report of a failing run
+func SetSeed(seed int64) {
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+	randomSeed = seed
+	generator = rand.New(rand.NewSource(seed))
+}
+
+// Seed returns the seed of the current run
+func Seed() int64 {
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+	return randomSeed
+}
+
+// SeedScenario restarts the generator from a seed derived from the run seed and the scenario name,
+// so a scenario produces the same values whether it runs alone or with the whole suite.
+func SeedScenario(scenario string) {
+	hash := fnv.New64a()
+	hash.Write([]byte(scenario))
+
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+	generator = rand.New(rand.NewSource(randomSeed ^ int64(hash.Sum64())))
+}
+
+// withGenerator runs a function with exclusive use of the generator
+func withGenerator(f func(r *rand.Rand)) {
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+	f(generator)
+}
+
+// RandomInt returns a number between min and max, both included
+func RandomInt(min, max int) int {
+	if max < min {
+		min, max = max, min
+	}
+	var n int
+	withGenerator(func(r *rand.Rand) { n = min + r.Intn(max-min+1) })
+	return n
+}
+
+// RandomDigits returns a string of n random digits, which may start with 0
+func RandomDigits(n int) string {
+	var digits strings.Builder
+	withGenerator(func(r *rand.Rand) {
+		for i := 0; i < n; i++ {
+			digits.WriteByte(byte('0' + r.Intn(10)))
+		}
+	})
+	return digits.String()
+}
+
+// RandomChoice returns one of the options
+func RandomChoice(options ...string) string {
+	if len(options) == 0 {
+		return ""
+	}
+	return options[RandomInt(0, len(options)-1)]
+}
+
+// RandomFirstName returns a first name
+func RandomFirstName() string {
+	return RandomChoice(firstNames...)
+}
+
+// RandomLastName returns a family name
+func RandomLastName() string {
+	return RandomChoice(lastNames...)
+}
+
+// RandomFullName returns a first name followed by a family name
+func RandomFullName() string {
+	return RandomFirstName() + " " + RandomLastName()
+}
+
+// RandomAddress returns a UK address
+func RandomAddress() Address {
+	return Address{
+		Line1:       fmt.Sprintf("%d %s", RandomInt(1, 250), RandomChoice(streetNames...)),
+		City:        RandomChoice(cities...),
+		Postcode:    fmt.Sprintf("%c%c%d %d%c%c", RandomInt('A', 'Z'), RandomInt('A', 'Z'), RandomInt(1, 99), RandomInt(1, 9), RandomInt('A', 'Z'), RandomInt('A', 'Z')),
+		CountryCode: "GB",
+	}
+}
+
+// RandomPrice returns a retail price between min and max cents in GBP
+func RandomPrice(minCents, maxCents int) RetailPrice {
+	return RetailPrice{CentsValue: RandomInt(minCents, maxCents), Currency: "GBP"}
+}
+
+// RandomDateBetween returns a date (yyyy-MM-dd) between two dates, both included
+func RandomDateBetween(from, to time.Time) string {
+	days := int(to.Sub(from).Hours() / 24)
+	return from.AddDate(0, 0, RandomInt(0, days)).Format("2006-01-02")
+}
+
+// RandomFutureDate returns a date between tomorrow and maxDays from today
+func RandomFutureDate(maxDays int) string {
+	return FutureDateDays(RandomInt(1, maxDays))
+}
+
+// RandomPastDate returns a date between yesterday and maxDays before today
+func RandomPastDate(maxDays int) string {
+	return PastDateDays(RandomInt(1, maxDays))
+}
@@ -0,0 +1,86 @@
+package data_helpers
+
+type SKUBuilder struct {
+	sku SKU
+}
+
+// NewSKUBuilder starts from the default SKU of GenerateSKU, with one EACH unit of measure.
+func NewSKUBuilder() *SKUBuilder {
+	return &SKUBuilder{sku: GenerateSKU()}
+}
+
+func (b *SKUBuilder) WithSKUId(skuId string) *SKUBuilder {
+	b.sku.SKUId = skuId
+	return b
+}
+
+func (b *SKUBuilder) WithDescription(description string) *SKUBuilder {
+	b.sku.Description = description
+	return b
+}
+
+// WithUom adds a unit of measure to the default one; use WithoutUoms first to replace it.
+func (b *SKUBuilder) WithUom(uom SKUUom) *SKUBuilder {
+	b.sku.SKUUom = append(b.sku.SKUUom, uom)
+	return b
+}
+
+func (b *SKUBuilder) WithoutUoms() *SKUBuilder {
+	b.sku.SKUUom = []SKUUom{}
+	return b
+}
+
+func (b *SKUBuilder) WithSupplier(supplierID, supplierReference string) *SKUBuilder {
+	b.sku.SupplierID = supplierID
+	b.sku.SupplierReference = supplierReference
+	return b
+}
+
+func (b *SKUBuilder) WithMinimumLife(onReceipt, onDespatch int) *SKUBuilder {
+	b.sku.MinimumLifeOnReceipt = onReceipt
+	b.sku.MinimumLifeOnDespatch = onDespatch
+	return b
+}
+
+func (b *SKUBuilder) WithRetailPrice(centsValue int, currency string) *SKUBuilder {
+	b.sku.RetailPrice = RetailPrice{CentsValue: centsValue, Currency: currency}
+	return b
+}
+
+func (b *SKUBuilder) WithCountryOfOrigin(countryOfOrigin string) *SKUBuilder {
+	b.sku.CountryOfOrigin = countryOfOrigin
+	return b
+}
+
+// WithBarcode adds a barcode to the default one; use WithoutBarcodes first to replace it.
+func (b *SKUBuilder) WithBarcode(barcode, barcodeType string) *SKUBuilder {
+	b.sku.SKUBarcodes = append(b.sku.SKUBarcodes, Barcode{Barcode: barcode, BarcodeType: barcodeType})
+	return b
+}
+
+func (b *SKUBuilder) WithoutBarcodes() *SKUBuilder {
+	b.sku.SKUBarcodes = []Barcode{}
+	return b
+}
+
+func (b *SKUBuilder) Build() SKU {
+	return b.sku
+}
+
+// NewUom creates a unit of measure with dimensions in millimetres, volume in cubic centimetres and weight in grams.
+func NewUom(unitOfMeasure string, height, width, depth, weight int) SKUUom {
+	return SKUUom{
+		UnitOfMeasure: unitOfMeasure,
+		Height:        ScalarUnit{Scalar: height, Units: "MM"},
+		Width:         ScalarUnit{Scalar: width, Units: "MM"},
+		Depth:         ScalarUnit{Scalar: depth, Units: "MM"},
+		Volume:        ScalarUnit{Scalar: height * width * depth / 1000, Units: "CC"},
+		Weight:        ScalarUnit{Scalar: weight, Units: "G"},
+	}
+}
+
+// WithUnitsPerParent adds how many of this unit of measure make up a parent unit, e.g. 12 EACH per CASE.
+func WithUnitsPerParent(uom SKUUom, parentUnitOfMeasure string, noOfUnits int) SKUUom {
+	uom.UnitsPerParent = append(uom.UnitsPerParent, UnitsPerParent{UnitOfMeasure: parentUnitOfMeasure, NoOfUnits: noOfUnits})
+	return uom
+}
@@ -0,0 +1,115 @@
+package data_helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"test-in-go/utils/logging_helpers"
+)
+
+// testIDPattern matches a test ID "<category>-<method>-<testcase>", e.g. "110-010-001"
+var testIDPattern = regexp.MustCompile(`^(\d{3})-(\d{3})-(\d{3})$`)
+
+// Ranges of the test ID parts that keep the 4-digit test code unique: 20 categories of 10 methods of 49 test cases
+const (
+	maxTestCategory = 200
+	maxTestMethod   = 100
+	maxTestcase     = 49
+	maxTestRound    = 9
+)
+
+// TestRoundFile keeps the round of the last run of the day, so that reruns on the same day get new IDs
+var TestRoundFile = filepath.Join(".", "reports", "test_round.json")
+
+// Round of today's runs allocated to this run, the first digit of every TestCode, and the test variables derived from it
+var (
+	TestRound     = 1
+	TestVariables = GenerateTestVariables(TestRound)
+)
+
+// testRoundState is the content of TestRoundFile
+type testRoundState struct {
+	Date  string `json:"date"`
+	Round int    `json:"round"`
+}
+
+// ValidateTestID checks a test ID against the naming convention "<category>-<method>-<testcase>":
+// the category is 010-200 and the method 010-100, both multiples of 10, and the test case is 001-049.
+// Within these ranges every test ID has its own test code.
+func ValidateTestID(testID string) error {
+	_, err := TestCodeFor(testID)
+	return err
+}
+
+// TestCodeFor returns the 4-digit test code of a test ID:
+// ((category / 10 - 1) * 500) + ((method / 10 - 1) * 50) + testcase
+func TestCodeFor(testID string) (string, error) {
+	match := testIDPattern.FindStringSubmatch(testID)
+	if match == nil {
+		return "", fmt.Errorf("invalid test ID %q: expected <category>-<method>-<testcase> such as 110-010-001", testID)
+	}
+	category, _ := strconv.Atoi(match[1])
+	method, _ := strconv.Atoi(match[2])
+	testcase, _ := strconv.Atoi(match[3])
+
+	if category < 10 || category > maxTestCategory || category%10 != 0 {
+		return "", fmt.Errorf("invalid test ID %q: category %s must be a multiple of 10 from 010 to %03d", testID, match[1], maxTestCategory)
+	}
+	if method < 10 || method > maxTestMethod || method%10 != 0 {
+		return "", fmt.Errorf("invalid test ID %q: method %s must be a multiple of 10 from 010 to %03d", testID, match[2], maxTestMethod)
+	}
+	if testcase < 1 || testcase > maxTestcase {
+		return "", fmt.Errorf("invalid test ID %q: test case %s must be from 001 to %03d", testID, match[3], maxTestcase)
+	}
+
+	tcID := ((category/10 - 1) * 500) + ((method/10 - 1) * 50) + testcase
+	return fmt.Sprintf("%04d", tcID), nil
+}
+
+// AllocateTestRound gives this run the next round of the day, 1 to 9, and stores it in TestRoundFile.
+// The first run of a day, by the framework clock, is round 1. The round is the first digit of every TestCode,
+// so from the tenth run on the same day round 9 is reused, with a warning, rather than starting again at round 1.
+func AllocateTestRound() (int, error) {
+	today := TestDate()
+	state := testRoundState{}
+	if data, err := os.ReadFile(TestRoundFile); err == nil {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return 0, fmt.Errorf("invalid test round file %s: %v", TestRoundFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("could not read test round file %s: %v", TestRoundFile, err)
+	}
+
+	round := 1
+	if state.Date == today {
+		round = state.Round + 1
+		if round > maxTestRound {
+			logging_helpers.Logger().Warnf("All %d test rounds of %s are used, reusing round %d: its product codes and barcodes may already exist. "+
+				"Remove %s once the data of the earlier rounds is cleaned up to start again at round 1.", maxTestRound, today, maxTestRound, TestRoundFile)
+			round = maxTestRound
+		}
+	}
+
+	data, err := json.MarshalIndent(testRoundState{Date: today, Round: round}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(TestRoundFile), 0755); err != nil {
+		return 0, fmt.Errorf("could not create %s: %v", filepath.Dir(TestRoundFile), err)
+	}
+	if err := os.WriteFile(TestRoundFile, data, 0644); err != nil {
+		return 0, fmt.Errorf("could not write test round file %s: %v", TestRoundFile, err)
+	}
+
+	SetTestRound(round)
+	return round, nil
+}
+
+// SetTestRound makes round the test round of the run without allocating it, e.g. the round a replayed cassette was recorded in
+func SetTestRound(round int) {
+	TestRound = round
+	TestVariables = GenerateTestVariables(round)
+}
@@ -0,0 +1,127 @@
+package data_helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTestCodeFor(t *testing.T) {
+	tests := []struct {
+		testID  string
+		want    string
+		wantErr bool
+	}{
+		{testID: "010-010-001", want: "0001"},
+		{testID: "010-010-049", want: "0049"},
+		{testID: "010-020-001", want: "0051"},
+		{testID: "020-010-001", want: "0501"},
+		{testID: "110-010-001", want: "5001"},
+		{testID: "110-010-004", want: "5004"},
+		{testID: "200-100-049", want: "9999"},
+		{testID: "000-010-001", wantErr: true},
+		{testID: "015-010-001", wantErr: true},
+		{testID: "210-010-001", wantErr: true},
+		{testID: "010-000-001", wantErr: true},
+		{testID: "010-110-001", wantErr: true},
+		{testID: "010-010-000", wantErr: true},
+		{testID: "010-010-050", wantErr: true},
+		{testID: "10-10-1", wantErr: true},
+		{testID: "110-010-001 ", wantErr: true},
+		{testID: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testID, func(t *testing.T) {
+			got, err := TestCodeFor(tt.testID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TestCodeFor(%q) error = %v, wantErr %v", tt.testID, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TestCodeFor(%q) = %q, want %q", tt.testID, got, tt.want)
+			}
+			if err := ValidateTestID(tt.testID); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTestID(%q) error = %v, wantErr %v", tt.testID, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTestCodesAreUnique checks that no two valid test IDs share a test code
+func TestTestCodesAreUnique(t *testing.T) {
+	seen := make(map[string]string)
+	for category := 10; category <= maxTestCategory; category += 10 {
+		for method := 10; method <= maxTestMethod; method += 10 {
+			for testcase := 1; testcase <= maxTestcase; testcase++ {
+				testID := fmt.Sprintf("%03d-%03d-%03d", category, method, testcase)
+				code, err := TestCodeFor(testID)
+				if err != nil {
+					t.Fatalf("TestCodeFor(%q) error = %v", testID, err)
+				}
+				if other, ok := seen[code]; ok {
+					t.Fatalf("test IDs %s and %s share the test code %s", other, testID, code)
+				}
+				seen[code] = testID
+			}
+		}
+	}
+}
+
+func TestAllocateTestRound(t *testing.T) {
+	today := TestDate()
+	tests := []struct {
+		name    string
+		state   string // content of the round file, none when empty
+		want    int
+		wantErr bool
+	}{
+		{name: "first run of the day", want: 1},
+		{name: "round of an earlier day", state: `{"date": "2000-01-01", "round": 5}`, want: 1},
+		{name: "next round of today", state: `{"date": "` + today + `", "round": 3}`, want: 4},
+		{name: "last round of today", state: `{"date": "` + today + `", "round": 8}`, want: 9},
+		{name: "all rounds used reuses the last", state: `{"date": "` + today + `", "round": 9}`, want: 9},
+		{name: "invalid round file", state: `{"date":`, wantErr: true},
+	}
+
+	previousFile, previousRound := TestRoundFile, TestRound
+	t.Cleanup(func() {
+		TestRoundFile = previousFile
+		SetTestRound(previousRound)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			TestRoundFile = filepath.Join(t.TempDir(), "reports", "test_round.json")
+			if tt.state != "" {
+				if err := os.MkdirAll(filepath.Dir(TestRoundFile), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(TestRoundFile, []byte(tt.state), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := AllocateTestRound()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AllocateTestRound() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want || TestRound != tt.want {
+				t.Errorf("AllocateTestRound() = %d, TestRound = %d, want %d", got, TestRound, tt.want)
+			}
+
+			var state testRoundState
+			data, err := os.ReadFile(TestRoundFile)
+			if err == nil {
+				err = json.Unmarshal(data, &state)
+			}
+			if err != nil || state.Date != today || state.Round != tt.want {
+				t.Errorf("round file = %+v (%v), want round %d of %s", state, err, tt.want, today)
+			}
+		})
+	}
+}
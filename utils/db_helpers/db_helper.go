@@ -1,20 +1,150 @@
 package db_helpers
 
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// DefaultDatasource is the name under which the main database (config "db") is registered
+const DefaultDatasource = "default"
+
+// Identifiers are validated rather than quoted so PostgreSQL folds them to lower case as in hand-written queries
+var (
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	tablePattern      = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*\.)?[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
 // DBInterface abstracts common database operations.
 type DBInterface interface {
 	Connect(connectionString string) error
 	Close() error
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// DBManager implements the DBInterface for a single named datasource.
+// A DBManager returned by DatasourceFor is bound to a scenario transaction and runs its queries inside it.
+type DBManager struct {
+	Name string
+	Pool PoolSettings
+	db   *sql.DB
+	tx   *sql.Tx
+}
+
+// executor is implemented by both *sql.DB and *sql.Tx
+type executor interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
 }
 
-// DBManager implements the DBInterface
-type DBManager struct{}
+var (
+	datasourcesMu sync.RWMutex
+	datasources   = make(map[string]*DBManager)
+)
 
 // Connect establishes a connection to the database.
 func (d *DBManager) Connect(connectionString string) error {
-	return ConnectPostgres(connectionString)
+	db, err := openPostgres(connectionString, d.Pool)
+	if err != nil {
+		return err
+	}
+	d.db = db
+	return nil
 }
 
 // Close closes the database connection.
 func (d *DBManager) Close() error {
-	return ClosePostgres()
+	if d.db == nil {
+		return nil
+	}
+	if err := d.db.Close(); err != nil {
+		return fmt.Errorf("could not close datasource %q: %v", d.Name, err)
+	}
+	d.db = nil
+	return nil
+}
+
+// Query runs a query that returns rows.
+func (d *DBManager) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.executor().Query(query, args...)
+}
+
+// QueryRow runs a query that is expected to return at most one row.
+func (d *DBManager) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.executor().QueryRow(query, args...)
+}
+
+// Exec runs a statement that returns no rows.
+func (d *DBManager) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.executor().Exec(query, args...)
+}
+
+// executor returns the scenario transaction the manager is bound to, or the connection pool
+func (d *DBManager) executor() executor {
+	if d.tx != nil {
+		return d.tx
+	}
+	return d.db
+}
+
+// ConnectDatasource opens a named datasource and registers it for retrieval by name
+func ConnectDatasource(name, connectionString string, pool PoolSettings) error {
+	if name == DefaultDatasource {
+		return fmt.Errorf("datasource name %q is reserved for the default database", name)
+	}
+	manager := &DBManager{Name: name, Pool: pool}
+	if err := manager.Connect(connectionString); err != nil {
+		return fmt.Errorf("datasource %q: %v", name, err)
+	}
+	registerDatasource(manager)
+	return nil
+}
+
+// Datasource returns the connected datasource with the given name
+func Datasource(name string) (*DBManager, error) {
+	datasourcesMu.RLock()
+	defer datasourcesMu.RUnlock()
+	manager, ok := datasources[name]
+	if !ok {
+		return nil, fmt.Errorf("datasource %q is not configured (available: %v)", name, datasourceNames())
+	}
+	return manager, nil
+}
+
+// CloseDatasources closes every registered datasource
+func CloseDatasources() error {
+	datasourcesMu.Lock()
+	defer datasourcesMu.Unlock()
+	var firstErr error
+	for name, manager := range datasources {
+		if err := manager.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(datasources, name)
+	}
+	return firstErr
+}
+
+func registerDatasource(manager *DBManager) {
+	datasourcesMu.Lock()
+	defer datasourcesMu.Unlock()
+	if existing, ok := datasources[manager.Name]; ok && existing != manager {
+		existing.Close()
+	}
+	datasources[manager.Name] = manager
+}
+
+// datasourceNames lists the registered datasources; callers must hold datasourcesMu
+func datasourceNames() []string {
+	names := make([]string, 0, len(datasources))
+	for name := range datasources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
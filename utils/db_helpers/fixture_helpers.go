@@ -0,0 +1,114 @@
+package db_helpers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FixtureDir is the directory holding the named SQL fixtures (<name>.sql)
+var FixtureDir = filepath.Join(".", "fixtures")
+
+// CreatedRow identifies a row created during a scenario that must be deleted afterwards
+type CreatedRow struct {
+	Datasource string
+	Table      string
+	Column     string
+	Value      interface{}
+}
+
+var (
+	createdRowsMu sync.Mutex
+	createdRows   []CreatedRow
+)
+
+// ApplyFixture runs the SQL fixture with the given name on the default datasource, inside the scenario
+// transaction of ctx if any. The name may be qualified with a datasource, e.g. "orders:seed_orders".
+func ApplyFixture(ctx context.Context, name string) error {
+	datasource := DefaultDatasource
+	if parts := strings.SplitN(name, ":", 2); len(parts) == 2 {
+		datasource, name = parts[0], parts[1]
+	}
+	return ApplyFixtureIn(ctx, datasource, name)
+}
+
+// ApplyFixtureIn runs the SQL fixture with the given name on a named datasource, inside the scenario transaction of ctx if any.
+func ApplyFixtureIn(ctx context.Context, datasource, name string) error {
+	manager, err := DatasourceFor(ctx, datasource)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(FixtureDir, name+".sql")
+	script, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read fixture %q: %v", name, err)
+	}
+
+	// Without arguments the whole script is sent as one simple query, so it may hold several statements
+	if _, err := manager.Exec(string(script)); err != nil {
+		return fmt.Errorf("could not apply fixture %q on %q: %v", name, datasource, err)
+	}
+	log.Printf("Applied fixture %s on %s.", name, datasource)
+	return nil
+}
+
+// RecordCreatedRow remembers a row created by the current scenario so CleanupCreatedRows can delete it.
+func RecordCreatedRow(datasource, table, column string, value interface{}) {
+	createdRowsMu.Lock()
+	defer createdRowsMu.Unlock()
+	createdRows = append(createdRows, CreatedRow{Datasource: datasource, Table: table, Column: column, Value: value})
+}
+
+// CleanupCreatedRows deletes every recorded row, newest first, and forgets them.
+func CleanupCreatedRows() error {
+	createdRowsMu.Lock()
+	rows := createdRows
+	createdRows = nil
+	createdRowsMu.Unlock()
+
+	var firstErr error
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		if err := deleteRows(row.Datasource, row.Table, row.Column, "=", row.Value); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// DiscardCreatedRows forgets the recorded rows without deleting them, e.g. after a rolled back transaction.
+func DiscardCreatedRows() {
+	createdRowsMu.Lock()
+	defer createdRowsMu.Unlock()
+	createdRows = nil
+}
+
+// DeleteRowsWithPrefix deletes the rows of a table whose key column starts with the prefix, e.g. "PRD-0001".
+func DeleteRowsWithPrefix(datasource, table, column, prefix string) error {
+	if prefix == "" {
+		return fmt.Errorf("refusing to delete from %s without a key prefix", table)
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+	return deleteRows(datasource, table, column, "LIKE", escaped+"%")
+}
+
+// deleteRows deletes the rows of a table whose column compares to the value with the given operator
+func deleteRows(datasource, table, column, operator string, value interface{}) error {
+	if !tablePattern.MatchString(table) || !identifierPattern.MatchString(column) {
+		return fmt.Errorf("invalid table or column name %s.%s", table, column)
+	}
+	manager, err := Datasource(datasource)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s %s $1", table, column, operator)
+	if _, err := manager.Exec(query, value); err != nil {
+		return fmt.Errorf("could not clean up %s on %q: %v", table, datasource, err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package db_helpers
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MigrationDir is the directory holding the versioned migration files of the default datasource;
+// the migrations of a named datasource live in a subdirectory with its name
+var MigrationDir = filepath.Join(".", "migrations")
+
+// migrationFilePattern matches files such as 0001_create_product_table.up.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// migrationTable tracks the applied migrations of a database
+const migrationTable = "schema_migrations"
+
+// Migration is a versioned schema change with its up and down scripts
+type Migration struct {
+	Version int
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// MigrationState describes whether a migration has been applied and when
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// MigrationDirFor returns the migration directory of a datasource
+func MigrationDirFor(datasource string) string {
+	if datasource == DefaultDatasource {
+		return MigrationDir
+	}
+	return filepath.Join(MigrationDir, datasource)
+}
+
+// LoadMigrations reads the migration files of a directory in version order; each version needs an up and a down script.
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read migrations: %v", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		script, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %s: %v", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.UpSQL = string(script)
+		} else {
+			migration.DownSQL = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" || migration.DownSQL == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrationStatus lists every migration with the time it was applied to the datasource, if it was.
+func MigrationStatus(datasource string) ([]MigrationState, error) {
+	manager, err := Datasource(datasource)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := LoadMigrations(MigrationDirFor(datasource))
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(manager)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, migration := range migrations {
+		state := MigrationState{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			appliedAt := appliedAt
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// MigrateUp applies up to steps pending migrations in version order (all of them when steps <= 0).
+func MigrateUp(datasource string, steps int) error {
+	states, err := MigrationStatus(datasource)
+	if err != nil {
+		return err
+	}
+	manager, _ := Datasource(datasource)
+
+	applied := 0
+	for _, state := range states {
+		if state.AppliedAt != nil {
+			continue
+		}
+		if steps > 0 && applied == steps {
+			break
+		}
+		if err := runMigration(manager, state.Migration, true); err != nil {
+			return err
+		}
+		applied++
+	}
+	log.Printf("Applied %d migration(s) on %s.", applied, datasource)
+	return nil
+}
+
+// MigrateDown reverts the latest steps applied migrations (one when steps <= 0).
+func MigrateDown(datasource string, steps int) error {
+	if steps <= 0 {
+		steps = 1
+	}
+	states, err := MigrationStatus(datasource)
+	if err != nil {
+		return err
+	}
+	manager, _ := Datasource(datasource)
+
+	reverted := 0
+	for i := len(states) - 1; i >= 0 && reverted < steps; i-- {
+		if states[i].AppliedAt == nil {
+			continue
+		}
+		if err := runMigration(manager, states[i].Migration, false); err != nil {
+			return err
+		}
+		reverted++
+	}
+	log.Printf("Reverted %d migration(s) on %s.", reverted, datasource)
+	return nil
+}
+
+// runMigration applies or reverts one migration and updates the tracking table in the same transaction
+func runMigration(manager *DBManager, migration Migration, up bool) error {
+	tx, err := manager.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin migration transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	script, tracking := migration.UpSQL, "INSERT INTO "+migrationTable+" (version, name) VALUES ($1, $2)"
+	args := []interface{}{migration.Version, migration.Name}
+	if !up {
+		script, tracking = migration.DownSQL, "DELETE FROM "+migrationTable+" WHERE version = $1"
+		args = args[:1]
+	}
+
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %v", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.Exec(tracking, args...); err != nil {
+		return fmt.Errorf("could not record migration %04d_%s: %v", migration.Version, migration.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit migration %04d_%s: %v", migration.Version, migration.Name, err)
+	}
+
+	direction := "Applied"
+	if !up {
+		direction = "Reverted"
+	}
+	log.Printf("%s migration %04d_%s.", direction, migration.Version, migration.Name)
+	return nil
+}
+
+// appliedMigrations creates the tracking table when needed and returns the applied versions
+func appliedMigrations(manager *DBManager) (map[int]time.Time, error) {
+	_, err := manager.Exec(`CREATE TABLE IF NOT EXISTS ` + migrationTable + ` (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s: %v", migrationTable, err)
+	}
+
+	rows, err := manager.Query("SELECT version, applied_at FROM " + migrationTable)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", migrationTable, err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("could not read %s: %v", migrationTable, err)
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
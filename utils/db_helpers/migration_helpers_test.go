@@ -0,0 +1,108 @@
+package db_helpers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "migrations in version order",
+			files: map[string]string{
+				"0002_add_barcode.up.sql":           "ALTER TABLE products ADD barcode text;",
+				"0002_add_barcode.down.sql":         "ALTER TABLE products DROP barcode;",
+				"0001_create_products.up.sql":       "CREATE TABLE products ();",
+				"0001_create_products.down.sql":     "DROP TABLE products;",
+				"0010_create_orders_table.up.sql":   "CREATE TABLE orders ();",
+				"0010_create_orders_table.down.sql": "DROP TABLE orders;",
+			},
+			want: []Migration{
+				{Version: 1, Name: "create_products", UpSQL: "CREATE TABLE products ();", DownSQL: "DROP TABLE products;"},
+				{Version: 2, Name: "add_barcode", UpSQL: "ALTER TABLE products ADD barcode text;", DownSQL: "ALTER TABLE products DROP barcode;"},
+				{Version: 10, Name: "create_orders_table", UpSQL: "CREATE TABLE orders ();", DownSQL: "DROP TABLE orders;"},
+			},
+		},
+		{
+			name: "other files are ignored",
+			files: map[string]string{
+				"0001_create_products.up.sql":   "up",
+				"0001_create_products.down.sql": "down",
+				"README.md":                     "notes",
+				"0002_draft.sql":                "draft",
+				"reporting_db/0001_x.up.sql":    "other datasource",
+			},
+			want: []Migration{{Version: 1, Name: "create_products", UpSQL: "up", DownSQL: "down"}},
+		},
+		{
+			name:  "empty directory",
+			files: map[string]string{},
+			want:  []Migration{},
+		},
+		{
+			name:    "missing down script",
+			files:   map[string]string{"0001_create_products.up.sql": "up"},
+			wantErr: true,
+		},
+		{
+			name:    "missing up script",
+			files:   map[string]string{"0001_create_products.down.sql": "down"},
+			wantErr: true,
+		},
+		{
+			name: "version used by two names",
+			files: map[string]string{
+				"0001_create_products.up.sql":   "up",
+				"0001_create_products.down.sql": "down",
+				"0001_create_orders.up.sql":     "up",
+				"0001_create_orders.down.sql":   "down",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := LoadMigrations(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadMigrations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMigrationsMissingDirectory(t *testing.T) {
+	if _, err := LoadMigrations(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadMigrations() of a missing directory succeeded, want an error")
+	}
+}
+
+func TestMigrationDirFor(t *testing.T) {
+	if got := MigrationDirFor(DefaultDatasource); got != MigrationDir {
+		t.Errorf("MigrationDirFor(%q) = %q, want %q", DefaultDatasource, got, MigrationDir)
+	}
+	if want := filepath.Join(MigrationDir, "reporting_db"); MigrationDirFor("reporting_db") != want {
+		t.Errorf("MigrationDirFor(%q) = %q, want %q", "reporting_db", MigrationDirFor("reporting_db"), want)
+	}
+}
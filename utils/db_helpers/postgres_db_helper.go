@@ -4,48 +4,95 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	_ "github.com/lib/pq"
 )
 
 var DB *sql.DB
 
-// ConnectPostgres connects to the PostgreSQL database using the URL provided in the configuration
-func ConnectPostgres(connectionString string) error {
-	var err error
+// PoolSettings holds the connection pool limits applied to a database handle.
+// Zero values keep the database/sql defaults.
+type PoolSettings struct {
+	MaxOpenConnections int
+	MaxIdleConnections int
+	ConnMaxLifetime    time.Duration
+	ConnMaxIdleTime    time.Duration
+}
 
-	DB, err = sql.Open("postgres", connectionString)
+// ConnectPostgres connects to the PostgreSQL database using the URL and pool settings provided in the configuration.
+// The connection is also registered as the default datasource.
+func ConnectPostgres(connectionString string, pool PoolSettings) error {
+	db, err := openPostgres(connectionString, pool)
 	if err != nil {
-		return fmt.Errorf("could not connect to the PostgreSQL database: %v", err)
-	}
-
-	// Test the connection
-	if err := DB.Ping(); err != nil {
-		return fmt.Errorf("could not ping the PostgreSQL database: %v", err)
+		return err
 	}
+	DB = db
+	registerDatasource(&DBManager{Name: DefaultDatasource, Pool: pool, db: db})
 
 	log.Println("Connected to PostgreSQL database successfully.")
 	return nil
 }
 
-// ClosePostgres closes the PostgreSQL connection
+// openPostgres opens a PostgreSQL handle, applies the pool settings and verifies the connection
+func openPostgres(connectionString string, pool PoolSettings) (*sql.DB, error) {
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the PostgreSQL database: %v", err)
+	}
+
+	// Apply the connection pool limits
+	if pool.MaxOpenConnections > 0 {
+		db.SetMaxOpenConns(pool.MaxOpenConnections)
+	}
+	if pool.MaxIdleConnections > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConnections)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	}
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not ping the PostgreSQL database: %v", err)
+	}
+	return db, nil
+}
+
+// ClosePostgres closes the PostgreSQL connection and every other registered datasource
 func ClosePostgres() error {
 	if DB != nil {
-		err := DB.Close()
+		err := CloseDatasources()
 		if err != nil {
 			return fmt.Errorf("could not close the PostgreSQL database: %v", err)
 		}
+		DB = nil
 		log.Println("Closed PostgreSQL database connection.")
 	}
 	return nil
 }
 
-// QueryDatabase abstracts the logic of querying the database.
-func QueryDatabase(query, id string) (string, error) {
-	var dbValue string
-	err := DB.QueryRow(query, id).Scan(&dbValue)
+// QueryDatabase abstracts the logic of querying the database for a single value.
+// A NULL value is returned as an empty string.
+func QueryDatabase(query string, args ...interface{}) (string, error) {
+	return QueryDatabaseIn(DefaultDatasource, query, args...)
+}
+
+// QueryDatabaseIn runs the same single-value query against a named datasource.
+func QueryDatabaseIn(datasource, query string, args ...interface{}) (string, error) {
+	manager, err := Datasource(datasource)
+	if err != nil {
+		return "", err
+	}
+
+	var dbValue sql.NullString
+	err = manager.QueryRow(query, args...).Scan(&dbValue)
 	if err != nil {
-		return "", fmt.Errorf("database query failed: %v", err)
+		return "", fmt.Errorf("database query failed on %q: %v", datasource, err)
 	}
-	return dbValue, nil
+	return dbValue.String, nil
 }
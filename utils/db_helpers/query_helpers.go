@@ -0,0 +1,289 @@
+package db_helpers
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryResult holds a complete result set together with the time it took to fetch it.
+// NULL columns are returned as nil; NUMERIC/DECIMAL values as float64; text and byte columns as string.
+type QueryResult struct {
+	Columns  []string
+	Rows     []map[string]interface{}
+	Duration time.Duration
+}
+
+// Select runs a query on the default datasource and returns every row.
+func Select(query string, args ...interface{}) (*QueryResult, error) {
+	manager, err := Datasource(DefaultDatasource)
+	if err != nil {
+		return nil, err
+	}
+	return manager.Select(query, args...)
+}
+
+// QueryRows runs a query on the default datasource and returns every row as a column/value map.
+func QueryRows(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	manager, err := Datasource(DefaultDatasource)
+	if err != nil {
+		return nil, err
+	}
+	return manager.QueryRows(query, args...)
+}
+
+// QueryValue runs a query on the default datasource and returns the first column of the first row.
+func QueryValue(query string, args ...interface{}) (interface{}, error) {
+	manager, err := Datasource(DefaultDatasource)
+	if err != nil {
+		return nil, err
+	}
+	return manager.QueryValue(query, args...)
+}
+
+// QueryStructs runs a query on the default datasource and scans the rows into dest, a pointer to a slice of structs.
+func QueryStructs(dest interface{}, query string, args ...interface{}) error {
+	manager, err := Datasource(DefaultDatasource)
+	if err != nil {
+		return err
+	}
+	return manager.QueryStructs(dest, query, args...)
+}
+
+// CountRows counts the rows of a table on the default datasource matching the optional where clause.
+func CountRows(table, where string, args ...interface{}) (int, error) {
+	manager, err := Datasource(DefaultDatasource)
+	if err != nil {
+		return 0, err
+	}
+	return manager.CountRows(table, where, args...)
+}
+
+// Select runs a query and returns every row with its columns and the query duration.
+func (d *DBManager) Select(query string, args ...interface{}) (*QueryResult, error) {
+	start := time.Now()
+	rows, err := d.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database query failed on %q: %v", d.Name, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("could not read columns: %v", err)
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("could not read column types: %v", err)
+	}
+
+	result := &QueryResult{Columns: columns}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("could not scan row: %v", err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = normalizeValue(values[i], columnTypes[i].DatabaseTypeName())
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database query failed on %q: %v", d.Name, err)
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// QueryRows runs a query and returns every row as a column/value map.
+func (d *DBManager) QueryRows(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	result, err := d.Select(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return result.Rows, nil
+}
+
+// QueryValue runs a query and returns the first column of the first row, failing when there is no row.
+func (d *DBManager) QueryValue(query string, args ...interface{}) (interface{}, error) {
+	result, err := d.Select(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) == 0 {
+		return nil, fmt.Errorf("database query failed on %q: %v", d.Name, sql.ErrNoRows)
+	}
+	return result.Rows[0][result.Columns[0]], nil
+}
+
+// CountRows counts the rows of a table matching the optional where clause, e.g. ("product", "productid LIKE $1", "PRD-%").
+func (d *DBManager) CountRows(table, where string, args ...interface{}) (int, error) {
+	if !tablePattern.MatchString(table) {
+		return 0, fmt.Errorf("invalid table name %q", table)
+	}
+	query := "SELECT COUNT(*) FROM " + table
+	if where != "" {
+		query += " WHERE " + where
+	}
+	var count int
+	if err := d.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("could not count rows in %s on %q: %v", table, d.Name, err)
+	}
+	return count, nil
+}
+
+// QueryStructs scans every row into dest, which must be a pointer to a slice of structs.
+// Columns are matched to fields by `db` tag, then `json` tag, then field name, ignoring case.
+// Fields implementing sql.Scanner (e.g. sql.NullString) and pointer fields receive NULLs; other fields keep their zero value.
+func (d *DBManager) QueryStructs(dest interface{}, query string, args ...interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice || slice.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a pointer to a slice of structs, got %T", dest)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+
+	result, err := d.Select(query, args...)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range result.Rows {
+		elem := reflect.New(elemType).Elem()
+		for column, value := range row {
+			field, ok := fieldForColumn(elem, column)
+			if !ok {
+				continue
+			}
+			if err := assignValue(field, value); err != nil {
+				return fmt.Errorf("column %s: %v", column, err)
+			}
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return nil
+}
+
+// normalizeValue converts driver values into plain Go values
+func normalizeValue(value interface{}, databaseType string) interface{} {
+	raw, isBytes := value.([]byte)
+	if !isBytes {
+		return value
+	}
+	switch databaseType {
+	case "NUMERIC", "DECIMAL":
+		if f, err := strconv.ParseFloat(string(raw), 64); err == nil {
+			return f
+		}
+	}
+	return string(raw)
+}
+
+// fieldForColumn finds the struct field a column should be scanned into
+func fieldForColumn(elem reflect.Value, column string) (reflect.Value, bool) {
+	elemType := elem.Type()
+	for i := 0; i < elemType.NumField(); i++ {
+		structField := elemType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		names := []string{
+			structField.Tag.Get("db"),
+			strings.Split(structField.Tag.Get("json"), ",")[0],
+			structField.Name,
+		}
+		for _, name := range names {
+			if name != "" && name != "-" && strings.EqualFold(name, column) {
+				return elem.Field(i), true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// assignValue stores a normalized database value in a struct field, converting where possible
+func assignValue(field reflect.Value, value interface{}) error {
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	target := field
+	if field.Kind() == reflect.Ptr {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+
+	source := reflect.ValueOf(value)
+	switch {
+	case source.Type().AssignableTo(target.Type()):
+		target.Set(source)
+	case target.Kind() == reflect.String:
+		target.SetString(fmt.Sprint(value))
+	case source.Type().ConvertibleTo(target.Type()) && isNumeric(source.Kind()) && isNumeric(target.Kind()):
+		target.Set(source.Convert(target.Type()))
+	default:
+		return fmt.Errorf("cannot assign %T to field of type %s", value, field.Type())
+	}
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(target.Addr())
+	}
+	return nil
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// InsertRow inserts a row built from a column/value map into a table of a named datasource.
+func InsertRow(datasource, table string, values map[string]interface{}) error {
+	if !tablePattern.MatchString(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+	manager, err := Datasource(datasource)
+	if err != nil {
+		return err
+	}
+
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		if !identifierPattern.MatchString(column) {
+			return fmt.Errorf("invalid column name %q", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	placeholders := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = values[column]
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	if _, err := manager.Exec(query, args...); err != nil {
+		return fmt.Errorf("could not insert into %s on %q: %v", table, datasource, err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package db_helpers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TableSnapshot holds the rows of a table whose key column starts with a prefix, keyed by that column
+type TableSnapshot struct {
+	Datasource string
+	Table      string
+	KeyColumn  string
+	Prefix     string
+	Rows       map[string]map[string]interface{}
+	manager    *DBManager
+}
+
+// ColumnChange describes a column whose value changed between two snapshots
+type ColumnChange struct {
+	Column string
+	Before string
+	After  string
+}
+
+// RowChange lists the changed columns of an updated row
+type RowChange struct {
+	Key     string
+	Columns []ColumnChange
+}
+
+// SnapshotDiff lists the rows inserted, updated and deleted between two snapshots of a table
+type SnapshotDiff struct {
+	Table    string
+	Inserted []string
+	Updated  []RowChange
+	Deleted  []string
+}
+
+// TakeSnapshot reads the rows of a table whose key column starts with the prefix,
+// inside the scenario transaction of ctx if any.
+func TakeSnapshot(ctx context.Context, datasource, table, keyColumn, prefix string) (*TableSnapshot, error) {
+	manager, err := DatasourceFor(ctx, datasource)
+	if err != nil {
+		return nil, err
+	}
+	return manager.takeSnapshot(table, keyColumn, prefix)
+}
+
+// takeSnapshot reads the rows of a table whose key column starts with the prefix
+func (d *DBManager) takeSnapshot(table, keyColumn, prefix string) (*TableSnapshot, error) {
+	if !tablePattern.MatchString(table) || !identifierPattern.MatchString(keyColumn) {
+		return nil, fmt.Errorf("invalid table or column name %s.%s", table, keyColumn)
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s LIKE $1", table, keyColumn)
+	rows, err := d.QueryRows(query, escaped+"%")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &TableSnapshot{
+		Datasource: d.Name,
+		Table:      table,
+		KeyColumn:  keyColumn,
+		Prefix:     prefix,
+		Rows:       make(map[string]map[string]interface{}, len(rows)),
+		manager:    d,
+	}
+	for _, row := range rows {
+		key, _ := lookupColumn(row, keyColumn)
+		snapshot.Rows[FormatValue(key)] = row
+	}
+	return snapshot, nil
+}
+
+// Retake reads the same rows again through the same datasource and transaction, typically after the step under observation.
+func (s *TableSnapshot) Retake() (*TableSnapshot, error) {
+	manager := s.manager
+	if manager == nil {
+		var err error
+		if manager, err = Datasource(s.Datasource); err != nil {
+			return nil, err
+		}
+	}
+	return manager.takeSnapshot(s.Table, s.KeyColumn, s.Prefix)
+}
+
+// Diff compares the snapshot with a later one of the same table.
+func (s *TableSnapshot) Diff(after *TableSnapshot) SnapshotDiff {
+	diff := SnapshotDiff{Table: s.Table}
+
+	for key, afterRow := range after.Rows {
+		beforeRow, existed := s.Rows[key]
+		if !existed {
+			diff.Inserted = append(diff.Inserted, key)
+			continue
+		}
+		var changes []ColumnChange
+		for column, afterValue := range afterRow {
+			before, after := FormatValue(beforeRow[column]), FormatValue(afterValue)
+			if before != after {
+				changes = append(changes, ColumnChange{Column: column, Before: before, After: after})
+			}
+		}
+		if len(changes) > 0 {
+			sort.Slice(changes, func(i, j int) bool { return changes[i].Column < changes[j].Column })
+			diff.Updated = append(diff.Updated, RowChange{Key: key, Columns: changes})
+		}
+	}
+	for key := range s.Rows {
+		if _, exists := after.Rows[key]; !exists {
+			diff.Deleted = append(diff.Deleted, key)
+		}
+	}
+
+	sort.Strings(diff.Inserted)
+	sort.Strings(diff.Deleted)
+	sort.Slice(diff.Updated, func(i, j int) bool { return diff.Updated[i].Key < diff.Updated[j].Key })
+	return diff
+}
+
+// Empty reports whether nothing changed
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Inserted) == 0 && len(d.Updated) == 0 && len(d.Deleted) == 0
+}
+
+// String renders the changes with column-level details for the report
+func (d SnapshotDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "table %q: %d inserted, %d updated, %d deleted", d.Table, len(d.Inserted), len(d.Updated), len(d.Deleted))
+	for _, key := range d.Inserted {
+		fmt.Fprintf(&b, "\n  + %s", key)
+	}
+	for _, change := range d.Updated {
+		fmt.Fprintf(&b, "\n  ~ %s", change.Key)
+		for _, column := range change.Columns {
+			fmt.Fprintf(&b, "\n      %s: %s -> %s", column.Column, strconv.Quote(column.Before), strconv.Quote(column.After))
+		}
+	}
+	for _, key := range d.Deleted {
+		fmt.Fprintf(&b, "\n  - %s", key)
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package db_helpers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTableSnapshotDiff(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	row := func(values ...interface{}) map[string]interface{} {
+		r := make(map[string]interface{})
+		for i := 0; i+1 < len(values); i += 2 {
+			r[values[i].(string)] = values[i+1]
+		}
+		return r
+	}
+	snapshot := func(rows map[string]map[string]interface{}) *TableSnapshot {
+		return &TableSnapshot{Table: "products", KeyColumn: "product_code", Prefix: "PRD-1", Rows: rows}
+	}
+
+	tests := []struct {
+		name   string
+		before map[string]map[string]interface{}
+		after  map[string]map[string]interface{}
+		want   SnapshotDiff
+	}{
+		{
+			name:   "no changes",
+			before: map[string]map[string]interface{}{"PRD-11": row("name", "Peas", "created_at", created)},
+			after:  map[string]map[string]interface{}{"PRD-11": row("name", "Peas", "created_at", created)},
+			want:   SnapshotDiff{Table: "products"},
+		},
+		{
+			name:   "inserted rows are sorted",
+			before: map[string]map[string]interface{}{},
+			after:  map[string]map[string]interface{}{"PRD-13": row("name", "b"), "PRD-12": row("name", "a")},
+			want:   SnapshotDiff{Table: "products", Inserted: []string{"PRD-12", "PRD-13"}},
+		},
+		{
+			name:   "deleted rows",
+			before: map[string]map[string]interface{}{"PRD-11": row("name", "Peas"), "PRD-12": row("name", "Beans")},
+			after:  map[string]map[string]interface{}{"PRD-12": row("name", "Beans")},
+			want:   SnapshotDiff{Table: "products", Deleted: []string{"PRD-11"}},
+		},
+		{
+			name:   "updated columns are sorted and formatted",
+			before: map[string]map[string]interface{}{"PRD-11": row("status", "NEW", "price", 1.5, "note", nil)},
+			after:  map[string]map[string]interface{}{"PRD-11": row("status", "ACTIVE", "price", 2.25, "note", "checked")},
+			want: SnapshotDiff{Table: "products", Updated: []RowChange{{Key: "PRD-11", Columns: []ColumnChange{
+				{Column: "note", Before: NullValue, After: "checked"},
+				{Column: "price", Before: "1.5", After: "2.25"},
+				{Column: "status", Before: "NEW", After: "ACTIVE"},
+			}}}},
+		},
+		{
+			name:   "values of different types with the same text are unchanged",
+			before: map[string]map[string]interface{}{"PRD-11": row("quantity", int64(3))},
+			after:  map[string]map[string]interface{}{"PRD-11": row("quantity", 3.0)},
+			want:   SnapshotDiff{Table: "products"},
+		},
+		{
+			name:   "new column counts as a change from NULL",
+			before: map[string]map[string]interface{}{"PRD-11": row("name", "Peas")},
+			after:  map[string]map[string]interface{}{"PRD-11": row("name", "Peas", "barcode", "123")},
+			want: SnapshotDiff{Table: "products", Updated: []RowChange{{Key: "PRD-11", Columns: []ColumnChange{
+				{Column: "barcode", Before: NullValue, After: "123"},
+			}}}},
+		},
+		{
+			name: "inserted, updated and deleted together",
+			before: map[string]map[string]interface{}{
+				"PRD-11": row("name", "Peas"),
+				"PRD-12": row("name", "Beans"),
+			},
+			after: map[string]map[string]interface{}{
+				"PRD-12": row("name", "Green Beans"),
+				"PRD-13": row("name", "Corn"),
+			},
+			want: SnapshotDiff{
+				Table:    "products",
+				Inserted: []string{"PRD-13"},
+				Updated:  []RowChange{{Key: "PRD-12", Columns: []ColumnChange{{Column: "name", Before: "Beans", After: "Green Beans"}}}},
+				Deleted:  []string{"PRD-11"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := snapshot(tt.before).Diff(snapshot(tt.after))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+			if got.Empty() != (len(tt.want.Inserted) == 0 && len(tt.want.Updated) == 0 && len(tt.want.Deleted) == 0) {
+				t.Errorf("Empty() = %v for %+v", got.Empty(), got)
+			}
+		})
+	}
+}
+
+func TestSnapshotDiffString(t *testing.T) {
+	diff := SnapshotDiff{
+		Table:    "products",
+		Inserted: []string{"PRD-13"},
+		Updated:  []RowChange{{Key: "PRD-12", Columns: []ColumnChange{{Column: "name", Before: "Beans", After: "Green Beans"}}}},
+		Deleted:  []string{"PRD-11"},
+	}
+	want := "table \"products\": 1 inserted, 1 updated, 1 deleted\n" +
+		"  + PRD-13\n" +
+		"  ~ PRD-12\n" +
+		"      name: \"Beans\" -> \"Green Beans\"\n" +
+		"  - PRD-11"
+	if got := diff.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+package db_helpers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"test-in-go/utils/data_helpers"
+	"time"
+)
+
+// NullValue is the notation used for SQL NULL in expected and actual table values
+const NullValue = "<null>"
+
+// ExpectedRow maps column names to expected values.
+// A value may start with a comparison operator: "!=", ">", ">=", "<", "<=", "~" (regular expression),
+// "contains" or "barcode" (a valid barcode of a format such as EAN13 or GS1), followed by a space and the operand;
+// "<null>", "<not null>" and "<any>" are also supported.
+// Any other value must equal the actual value.
+type ExpectedRow map[string]string
+
+// RowMismatch describes an expected row that was not found or whose columns differ
+type RowMismatch struct {
+	Key         string
+	NotFound    bool
+	ColumnDiffs []ColumnDiff
+}
+
+// ColumnDiff describes a column whose actual value does not satisfy the expectation
+type ColumnDiff struct {
+	Column   string
+	Expected string
+	Actual   string
+}
+
+// TableAssertionError lists every mismatching row of a table assertion
+type TableAssertionError struct {
+	Table      string
+	Expected   int
+	Mismatches []RowMismatch
+}
+
+// Error renders the mismatches as a readable expected vs actual diff
+func (e *TableAssertionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "table %q: %d of %d expected rows did not match", e.Table, len(e.Mismatches), e.Expected)
+	for _, mismatch := range e.Mismatches {
+		if mismatch.NotFound {
+			fmt.Fprintf(&b, "\n  row %s: not found", mismatch.Key)
+			continue
+		}
+		fmt.Fprintf(&b, "\n  row %s:", mismatch.Key)
+		for _, diff := range mismatch.ColumnDiffs {
+			fmt.Fprintf(&b, "\n    - %s: expected %s, actual %s", diff.Column, diff.Expected, diff.Actual)
+		}
+	}
+	return b.String()
+}
+
+// AssertTableContains checks on the default datasource that the table contains every expected row.
+func AssertTableContains(table string, keyColumns []string, rows []ExpectedRow) error {
+	manager, err := Datasource(DefaultDatasource)
+	if err != nil {
+		return err
+	}
+	return manager.AssertTableContains(table, keyColumns, rows)
+}
+
+// AssertTableContains checks that the table contains every expected row.
+// Rows are looked up by the values of the key columns; all other columns are compared with their expectations.
+func (d *DBManager) AssertTableContains(table string, keyColumns []string, rows []ExpectedRow) error {
+	if len(keyColumns) == 0 {
+		return fmt.Errorf("at least one key column is required to match rows in %q", table)
+	}
+	if !tablePattern.MatchString(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+
+	assertionErr := &TableAssertionError{Table: table, Expected: len(rows)}
+	for _, expected := range rows {
+		var conditions []string
+		var args []interface{}
+		var keyParts []string
+		for i, column := range keyColumns {
+			if !identifierPattern.MatchString(column) {
+				return fmt.Errorf("invalid key column name %q", column)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", column, i+1))
+			args = append(args, expected[column])
+			keyParts = append(keyParts, fmt.Sprintf("%s=%s", column, expected[column]))
+		}
+		key := strings.Join(keyParts, ", ")
+
+		query := fmt.Sprintf("SELECT * FROM %s WHERE %s", table, strings.Join(conditions, " AND "))
+		actualRows, err := d.QueryRows(query, args...)
+		if err != nil {
+			return err
+		}
+		if len(actualRows) == 0 {
+			assertionErr.Mismatches = append(assertionErr.Mismatches, RowMismatch{Key: key, NotFound: true})
+			continue
+		}
+
+		var diffs []ColumnDiff
+		for column, expectation := range expected {
+			actual, ok := lookupColumn(actualRows[0], column)
+			if !ok {
+				return fmt.Errorf("column %q does not exist in %q", column, table)
+			}
+			matched, err := MatchValue(expectation, actual)
+			if err != nil {
+				return fmt.Errorf("column %q: %v", column, err)
+			}
+			if !matched {
+				diffs = append(diffs, ColumnDiff{Column: column, Expected: strconv.Quote(expectation), Actual: strconv.Quote(FormatValue(actual))})
+			}
+		}
+		if len(diffs) > 0 {
+			sort.Slice(diffs, func(i, j int) bool { return diffs[i].Column < diffs[j].Column })
+			assertionErr.Mismatches = append(assertionErr.Mismatches, RowMismatch{Key: key, ColumnDiffs: diffs})
+		}
+	}
+
+	if len(assertionErr.Mismatches) > 0 {
+		return assertionErr
+	}
+	return nil
+}
+
+// MatchValue reports whether an actual database value satisfies an expectation (see ExpectedRow)
+func MatchValue(expectation string, actual interface{}) (bool, error) {
+	actualText := FormatValue(actual)
+
+	switch expectation {
+	case NullValue:
+		return actual == nil, nil
+	case "<not null>":
+		return actual != nil, nil
+	case "<any>":
+		return true, nil
+	}
+
+	operator, operand := splitOperator(expectation)
+	switch operator {
+	case "!=":
+		return actualText != operand, nil
+	case "~":
+		re, err := regexp.Compile(operand)
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression %q: %v", operand, err)
+		}
+		return actual != nil && re.MatchString(actualText), nil
+	case "contains":
+		return actual != nil && strings.Contains(actualText, operand), nil
+	case "barcode":
+		return actual != nil && data_helpers.ValidateBarcode(operand, actualText) == nil, nil
+	case ">", ">=", "<", "<=":
+		want, err := strconv.ParseFloat(operand, 64)
+		if err != nil {
+			return false, fmt.Errorf("operator %s needs a number, got %q", operator, operand)
+		}
+		got, err := strconv.ParseFloat(actualText, 64)
+		if err != nil {
+			return false, nil
+		}
+		switch operator {
+		case ">":
+			return got > want, nil
+		case ">=":
+			return got >= want, nil
+		case "<":
+			return got < want, nil
+		default:
+			return got <= want, nil
+		}
+	}
+
+	// Dates can be compared without their time part
+	if t, ok := actual.(time.Time); ok && len(expectation) == len("2006-01-02") {
+		return t.Format("2006-01-02") == expectation, nil
+	}
+	return actualText == expectation, nil
+}
+
+// FormatValue renders a database value the way it is written in feature files
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return NullValue
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// splitOperator separates a leading comparison operator from its operand
+func splitOperator(expectation string) (string, string) {
+	for _, operator := range []string{"!=", ">=", "<=", ">", "<", "~", "contains", "barcode"} {
+		if strings.HasPrefix(expectation, operator+" ") {
+			return operator, strings.TrimPrefix(expectation, operator+" ")
+		}
+	}
+	return "", expectation
+}
+
+// lookupColumn finds a column value ignoring case, as PostgreSQL folds unquoted identifiers to lower case
+func lookupColumn(row map[string]interface{}, column string) (interface{}, bool) {
+	if value, ok := row[column]; ok {
+		return value, true
+	}
+	for name, value := range row {
+		if strings.EqualFold(name, column) {
+			return value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,146 @@
+package db_helpers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// scenarioTransactions holds the transactions of one scenario, one per datasource, and its open savepoints.
+// It travels in the scenario's context, so scenarios running in parallel never share a transaction.
+type scenarioTransactions struct {
+	mu         sync.Mutex
+	txs        map[string]*sql.Tx
+	savepoints []string
+}
+
+// transactionsKey is the context key of the scenario transactions
+type transactionsKey struct{}
+
+// transactionsFrom returns the scenario transactions carried by ctx, or nil
+func transactionsFrom(ctx context.Context) *scenarioTransactions {
+	if ctx == nil {
+		return nil
+	}
+	transactions, _ := ctx.Value(transactionsKey{}).(*scenarioTransactions)
+	return transactions
+}
+
+// BeginTransactions starts a transaction on every connected datasource and returns a context carrying them.
+// Queries made through DatasourceFor with that context run inside them. When the context already carries
+// transactions, a savepoint is created on each of them instead.
+func BeginTransactions(ctx context.Context) (context.Context, error) {
+	if transactions := transactionsFrom(ctx); transactions != nil {
+		return ctx, transactions.savepoint()
+	}
+
+	transactions := &scenarioTransactions{txs: make(map[string]*sql.Tx)}
+	for _, manager := range connectedDatasources() {
+		tx, err := manager.db.Begin()
+		if err != nil {
+			transactions.rollback()
+			return ctx, fmt.Errorf("could not begin transaction on %q: %v", manager.Name, err)
+		}
+		transactions.txs[manager.Name] = tx
+	}
+	return context.WithValue(ctx, transactionsKey{}, transactions), nil
+}
+
+// RollbackTransactions undoes everything since the matching BeginTransactions: the latest savepoint,
+// or the whole transactions when no savepoint is left, in which case the returned context no longer carries them.
+func RollbackTransactions(ctx context.Context) (context.Context, error) {
+	transactions := transactionsFrom(ctx)
+	if transactions == nil {
+		return ctx, nil
+	}
+	if rolledBackToSavepoint, err := transactions.rollbackToSavepoint(); rolledBackToSavepoint {
+		return ctx, err
+	}
+	err := transactions.rollback()
+	return context.WithValue(ctx, transactionsKey{}, nil), err
+}
+
+// InTransaction reports whether ctx carries scenario transactions
+func InTransaction(ctx context.Context) bool {
+	return transactionsFrom(ctx) != nil
+}
+
+// DatasourceFor returns the named datasource bound to the scenario transaction carried by ctx, so that every
+// query made through it runs inside the transaction. Without a transaction it returns the datasource itself.
+func DatasourceFor(ctx context.Context, name string) (*DBManager, error) {
+	manager, err := Datasource(name)
+	if err != nil {
+		return nil, err
+	}
+	transactions := transactionsFrom(ctx)
+	if transactions == nil {
+		return manager, nil
+	}
+	transactions.mu.Lock()
+	defer transactions.mu.Unlock()
+	tx, ok := transactions.txs[name]
+	if !ok {
+		return manager, nil
+	}
+	return &DBManager{Name: manager.Name, Pool: manager.Pool, db: manager.db, tx: tx}, nil
+}
+
+// savepoint creates the next savepoint on every transaction
+func (t *scenarioTransactions) savepoint() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	savepoint := fmt.Sprintf("scenario_%d", len(t.savepoints)+1)
+	for name, tx := range t.txs {
+		if _, err := tx.Exec("SAVEPOINT " + savepoint); err != nil {
+			return fmt.Errorf("could not create savepoint on %q: %v", name, err)
+		}
+	}
+	t.savepoints = append(t.savepoints, savepoint)
+	return nil
+}
+
+// rollbackToSavepoint rolls every transaction back to the latest savepoint, reporting whether there was one
+func (t *scenarioTransactions) rollbackToSavepoint() (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(t.savepoints)
+	if n == 0 {
+		return false, nil
+	}
+	savepoint := t.savepoints[n-1]
+	t.savepoints = t.savepoints[:n-1]
+	var firstErr error
+	for name, tx := range t.txs {
+		if _, err := tx.Exec("ROLLBACK TO SAVEPOINT " + savepoint); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("could not roll back to savepoint on %q: %v", name, err)
+		}
+	}
+	return true, firstErr
+}
+
+// rollback rolls back every transaction and forgets them
+func (t *scenarioTransactions) rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var firstErr error
+	for name, tx := range t.txs {
+		if err := tx.Rollback(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("could not roll back transaction on %q: %v", name, err)
+		}
+	}
+	t.txs = nil
+	t.savepoints = nil
+	return firstErr
+}
+
+// connectedDatasources returns a snapshot of the registered datasources
+func connectedDatasources() []*DBManager {
+	datasourcesMu.RLock()
+	defer datasourcesMu.RUnlock()
+	managers := make([]*DBManager, 0, len(datasources))
+	for _, name := range datasourceNames() {
+		managers = append(managers, datasources[name])
+	}
+	return managers
+}
@@ -2,10 +2,26 @@ package logging_helpers
 
 import (
 	"os"
+	"sync"
+	"test-in-go/config"
 
 	"github.com/sirupsen/logrus"
 )
 
+// sharedLogger is the logger of the run, set up on first use
+var (
+	sharedLoggerOnce sync.Once
+	sharedLogger     *logrus.Logger
+)
+
+// Logger returns the logger of the run, shared by main and the helpers that have no logger passed to them
+func Logger() *logrus.Logger {
+	sharedLoggerOnce.Do(func() {
+		sharedLogger = SetupLogger()
+	})
+	return sharedLogger
+}
+
 // SetupLogger sets up the logger using Logrus
 func SetupLogger() *logrus.Logger {
 	log := logrus.New()
@@ -13,11 +29,25 @@ func SetupLogger() *logrus.Logger {
 	// Output logs to stdout
 	log.Out = os.Stdout
 
-	// Set the log format to JSON
-	log.SetFormatter(&logrus.JSONFormatter{})
+	// Set the log format to JSON, masking any resolved secrets
+	log.SetFormatter(&maskingFormatter{formatter: &logrus.JSONFormatter{}})
 
 	// Set the log level (you can set this dynamically based on environment)
 	log.SetLevel(logrus.InfoLevel)
 
 	return log
 }
+
+// maskingFormatter wraps a Logrus formatter and masks secret values in every formatted entry
+type maskingFormatter struct {
+	formatter logrus.Formatter
+}
+
+// Format formats the entry with the wrapped formatter and masks the result
+func (f *maskingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data, err := f.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(config.MaskSecrets(string(data))), nil
+}
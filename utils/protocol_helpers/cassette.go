@@ -0,0 +1,339 @@
+package protocol_helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"test-in-go/config"
+	"test-in-go/utils/data_helpers"
+)
+
+// Cassette modes
+const (
+	CassetteLive   = "live"
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// redactedValue replaces the values of redacted headers in cassettes
+const redactedValue = "******"
+
+// nonSlugCharacters are replaced when scenario and feature names are turned into file names
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Interaction is one recorded request with the response the application server gave to it
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request stored in a cassette
+type RecordedRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// RecordedResponse is the part of a response stored in a cassette
+type RecordedResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// Cassette holds the interactions of one scenario and the test round they were recorded in.
+// The round is part of every TestCode, so replay restores it for recorded paths such as /product/PRD-<testCode> to match.
+type Cassette struct {
+	Scenario     string        `json:"scenario"`
+	TestRound    int           `json:"testRound,omitempty"`
+	Interactions []Interaction `json:"interactions"`
+	path         string
+	used         []bool
+}
+
+// cassetteTransport records or replays the requests of the scenario whose cassette is inserted
+type cassetteTransport struct {
+	mu       sync.Mutex
+	mode     string
+	dir      string
+	match    []string
+	redact   []string
+	cassette *Cassette
+	names    map[string]int
+}
+
+var transport *cassetteTransport
+
+// cassettes returns the transport for the configured cassette mode, created on first use
+func cassettes() (*cassetteTransport, error) {
+	if transport != nil {
+		return transport, nil
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	mode := cfg.API.Cassettes.Mode
+	if mode == "" {
+		mode = CassetteLive
+	}
+	transport = &cassetteTransport{
+		mode:   mode,
+		dir:    cfg.API.Cassettes.Dir,
+		match:  cfg.API.Cassettes.Match,
+		redact: cfg.API.Cassettes.Redact,
+		names:  make(map[string]int),
+	}
+	return transport, nil
+}
+
+// InsertCassette selects the cassette of a scenario, identified by its feature file and name.
+// In replay mode the cassette must exist; in record mode a new one is started. Live mode does nothing.
+func InsertCassette(featureURI, scenario string) error {
+	t, err := cassettes()
+	if err != nil || t.mode == CassetteLive {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Scenario outline examples share a name, so repeated names get a numbered cassette
+	name := filepath.Join(cassetteDir(featureURI), slug(scenario))
+	t.names[name]++
+	if n := t.names[name]; n > 1 {
+		name = fmt.Sprintf("%s_%d", name, n)
+	}
+	path := filepath.Join(t.dir, name+".json")
+
+	cassette := &Cassette{Scenario: scenario, TestRound: data_helpers.TestRound, path: path}
+	if t.mode == CassetteReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("no cassette to replay for scenario %q: %v", scenario, err)
+		}
+		if err := json.Unmarshal(data, cassette); err != nil {
+			return fmt.Errorf("invalid cassette %s: %v", path, err)
+		}
+		cassette.used = make([]bool, len(cassette.Interactions))
+		if cassette.TestRound > 0 {
+			data_helpers.SetTestRound(cassette.TestRound)
+		}
+	}
+	t.cassette = cassette
+	return nil
+}
+
+// EjectCassette finishes the cassette of the current scenario, writing it to disk in record mode
+func EjectCassette() error {
+	t, err := cassettes()
+	if err != nil || t.mode == CassetteLive {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cassette := t.cassette
+	t.cassette = nil
+	if cassette == nil || t.mode != CassetteRecord {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cassette.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %v", err)
+	}
+	if err := os.WriteFile(cassette.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cassette %s: %v", cassette.path, err)
+	}
+	return nil
+}
+
+// RoundTrip sends live requests, records them or answers them from the inserted cassette
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == CassetteLive {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %v", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := RecordedRequest{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Query:   req.URL.RawQuery,
+		Headers: t.redactHeaders(req.Header),
+		Body:    config.MaskSecrets(string(body)),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cassette == nil {
+		return nil, fmt.Errorf("no cassette is inserted for %s %s", req.Method, req.URL.Path)
+	}
+
+	if t.mode == CassetteReplay {
+		return t.replay(req, recorded)
+	}
+	return t.record(req, recorded)
+}
+
+// record forwards the request and appends the exchange to the cassette
+func (t *cassetteTransport) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			Status:  resp.StatusCode,
+			Headers: t.redactHeaders(resp.Header),
+			Body:    config.MaskSecrets(string(body)),
+		},
+	})
+	return resp, nil
+}
+
+// replay answers with the first unused interaction matching the request
+func (t *cassetteTransport) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	for i, interaction := range t.cassette.Interactions {
+		if t.cassette.used[i] || !t.matches(interaction.Request, recorded) {
+			continue
+		}
+		t.cassette.used[i] = true
+
+		header := make(http.Header)
+		for name, value := range interaction.Response.Headers {
+			header.Set(name, value)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+			StatusCode:    interaction.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction in %s matches %s %s (matching on %s)",
+		t.cassette.path, recorded.Method, recorded.Path, strings.Join(t.matchRules(), ", "))
+}
+
+// matches compares a recorded request with a new one using the configured rules
+func (t *cassetteTransport) matches(recorded, actual RecordedRequest) bool {
+	for _, rule := range t.matchRules() {
+		switch {
+		case rule == "method":
+			if !strings.EqualFold(recorded.Method, actual.Method) {
+				return false
+			}
+		case rule == "path":
+			if recorded.Path != actual.Path {
+				return false
+			}
+		case rule == "query":
+			if recorded.Query != actual.Query {
+				return false
+			}
+		case rule == "body":
+			if normalizeJSON(recorded.Body) != normalizeJSON(actual.Body) {
+				return false
+			}
+		case strings.HasPrefix(rule, "header:"):
+			name := http.CanonicalHeaderKey(strings.TrimPrefix(rule, "header:"))
+			if recorded.Headers[name] != actual.Headers[name] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchRules returns the configured rules, defaulting to method and path
+func (t *cassetteTransport) matchRules() []string {
+	if len(t.match) == 0 {
+		return []string{"method", "path"}
+	}
+	return t.match
+}
+
+// redactHeaders flattens headers, replacing the values of the redacted ones
+func (t *cassetteTransport) redactHeaders(headers http.Header) map[string]string {
+	flattened := make(map[string]string, len(headers))
+	for name := range headers {
+		flattened[name] = config.MaskSecrets(headers.Get(name))
+	}
+	for _, name := range t.redact {
+		if _, exists := flattened[http.CanonicalHeaderKey(name)]; exists {
+			flattened[http.CanonicalHeaderKey(name)] = redactedValue
+		}
+	}
+	return flattened
+}
+
+// normalizeJSON re-encodes JSON bodies so formatting and key order do not affect matching
+func normalizeJSON(body string) string {
+	var document interface{}
+	if err := json.Unmarshal([]byte(body), &document); err != nil {
+		return body
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return body
+	}
+	return string(data)
+}
+
+// cassetteDir names the cassette directory of a feature after its path below the features directory,
+// e.g. inbound/product_creation for ./features/inbound/product_creation.feature, so that features with
+// the same file name in different directories keep their own cassettes
+func cassetteDir(featureURI string) string {
+	path := filepath.ToSlash(filepath.Clean(featureURI))
+	path = strings.TrimSuffix(path, filepath.Ext(path))
+	if i := strings.Index("/"+path, "/features/"); i >= 0 {
+		path = path[i+len("features/"):]
+	}
+
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		if part = slug(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+// slug turns a name into a lower-case file name
+func slug(name string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
@@ -0,0 +1,119 @@
+package protocol_helpers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"test-in-go/config"
+	"test-in-go/utils/report_helpers"
+	validationhelpers "test-in-go/utils/validation_helpers"
+)
+
+// Contract modes
+const (
+	ContractOff  = "off"
+	ContractWarn = "warn"
+	ContractFail = "fail"
+)
+
+// contractTransport validates every request and response against the OpenAPI document of the API
+type contractTransport struct {
+	next     http.RoundTripper
+	spec     *validationhelpers.OpenAPISpec
+	mode     string
+	basePath string
+}
+
+var contractSpec *validationhelpers.OpenAPISpec
+
+// withContract wraps a transport with contract validation when api.contract.mode is warn or fail
+func withContract(next http.RoundTripper) (http.RoundTripper, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	mode := cfg.API.Contract.Mode
+	if mode == "" || mode == ContractOff {
+		return next, nil
+	}
+
+	if contractSpec == nil {
+		spec, err := validationhelpers.LoadOpenAPISpec(cfg.API.Contract.Spec)
+		if err != nil {
+			return nil, err
+		}
+		contractSpec = spec
+	}
+
+	basePath := ""
+	if baseURL, err := url.Parse(config.GetEnv("API_URL")); err == nil {
+		basePath = strings.TrimSuffix(baseURL.Path, "/")
+	}
+	return &contractTransport{next: next, spec: contractSpec, mode: mode, basePath: basePath}, nil
+}
+
+// RoundTrip sends the request and checks both sides of the exchange against the operation it belongs to
+func (t *contractTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := bufferBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %v", err)
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, t.basePath)
+	operation, err := t.spec.FindOperation(req.Method, path)
+	if err != nil {
+		if reportErr := t.report(req.Method, path, []string{err.Error()}); reportErr != nil {
+			return nil, reportErr
+		}
+		return t.next.RoundTrip(req)
+	}
+
+	violations := t.spec.ValidateRequest(operation, req.Header.Get("Content-Type"), requestBody)
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := bufferBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	violations = append(violations, t.spec.ValidateResponse(operation, resp.StatusCode, resp.Header.Get("Content-Type"), responseBody)...)
+
+	if err := t.report(req.Method, path, violations); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// report writes contract violations to the report, and turns them into an error in fail mode
+func (t *contractTransport) report(method, path string, violations []string) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	details := fmt.Sprintf("%s %s violates %s: %s", method, path, t.spec.Path, strings.Join(violations, "; "))
+	if t.mode == ContractFail {
+		report_helpers.PrettyLogStep("Contract validation", "Failed", details)
+		return fmt.Errorf("contract violation: %s", details)
+	}
+	report_helpers.PrettyLogStep("Contract validation", "Warning", details)
+	return nil
+}
+
+// bufferBody reads a request or response body and replaces it with a re-readable copy
+func bufferBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
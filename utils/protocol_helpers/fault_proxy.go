@@ -0,0 +1,247 @@
+package protocol_helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"test-in-go/config"
+	"time"
+)
+
+// Fault kinds
+const (
+	FaultStatus  = "status"
+	FaultDelay   = "delay"
+	FaultDrop    = "drop"
+	FaultCorrupt = "corrupt"
+)
+
+// Fault describes a failure injected into the requests of a route.
+// Route is a path below the API base URL, optionally preceded by a method ("POST /product")
+// and optionally ending in * to match every path with that prefix.
+// Times limits how many requests are affected; zero affects every request.
+type Fault struct {
+	Route  string
+	Kind   string
+	Status int
+	Delay  time.Duration
+	Times  int
+	method string
+	path   string
+	hits   int
+}
+
+// faultProxy is a reverse proxy in front of the API that applies the injected faults
+type faultProxy struct {
+	mu       sync.Mutex
+	target   *url.URL
+	basePath string
+	url      string
+	proxy    *httputil.ReverseProxy
+	faults   []*Fault
+}
+
+// The running proxy, started on the first injected fault
+var (
+	proxyMu sync.Mutex
+	proxy   *faultProxy
+)
+
+// runningFaultProxy returns the running proxy, or nil before the first fault
+func runningFaultProxy() *faultProxy {
+	proxyMu.Lock()
+	defer proxyMu.Unlock()
+	return proxy
+}
+
+// InjectFault adds a fault for the following requests, starting the proxy in front of the API on first use.
+func InjectFault(fault Fault) error {
+	method, path, err := parseRoute(fault.Route)
+	if err != nil {
+		return err
+	}
+	switch fault.Kind {
+	case FaultStatus:
+		if fault.Status < 100 || fault.Status > 599 {
+			return fmt.Errorf("invalid HTTP status %d", fault.Status)
+		}
+	case FaultDelay, FaultDrop, FaultCorrupt:
+	default:
+		return fmt.Errorf("unknown fault kind %q", fault.Kind)
+	}
+	if fault.Times < 0 {
+		return fmt.Errorf("a fault cannot apply %d times", fault.Times)
+	}
+	fault.method, fault.path = method, path
+
+	p, err := startFaultProxy()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faults = append(p.faults, &fault)
+	return nil
+}
+
+// ResetFaults removes every injected fault; the proxy keeps forwarding requests unchanged.
+func ResetFaults() {
+	p := runningFaultProxy()
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faults = nil
+}
+
+// apiBaseURL returns the base URL requests are sent to: the fault proxy once it runs, otherwise the API itself
+func apiBaseURL() string {
+	if p := runningFaultProxy(); p != nil {
+		return p.url
+	}
+	return config.GetEnv("API_URL")
+}
+
+// startFaultProxy starts the proxy on a free local port, forwarding to the configured API base URL
+func startFaultProxy() (*faultProxy, error) {
+	proxyMu.Lock()
+	defer proxyMu.Unlock()
+	if proxy != nil {
+		return proxy, nil
+	}
+
+	target, err := url.Parse(config.GetEnv("API_URL"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API URL: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the fault proxy: %v", err)
+	}
+
+	p := &faultProxy{
+		target:   target,
+		basePath: strings.TrimSuffix(target.Path, "/"),
+		url:      "http://" + listener.Addr().String() + strings.TrimSuffix(target.Path, "/"),
+		proxy:    httputil.NewSingleHostReverseProxy(&url.URL{Scheme: target.Scheme, Host: target.Host}),
+	}
+	// Send the API's own host name, not the proxy's address, so virtual hosts and ingresses route the request
+	director := p.proxy.Director
+	p.proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+	}
+	p.proxy.ModifyResponse = p.modifyResponse
+	go http.Serve(listener, p)
+
+	proxy = p
+	return proxy, nil
+}
+
+// ServeHTTP applies the first active fault of the request's route before or instead of forwarding it
+func (p *faultProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fault := p.takeFault(r.Method, strings.TrimPrefix(r.URL.Path, p.basePath))
+	if fault == nil {
+		p.proxy.ServeHTTP(w, r)
+		return
+	}
+
+	switch fault.Kind {
+	case FaultStatus:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(fault.Status)
+		fmt.Fprintf(w, `{"error": "fault injected by the test framework", "status": %d}`, fault.Status)
+	case FaultDelay:
+		time.Sleep(fault.Delay)
+		p.proxy.ServeHTTP(w, r)
+	case FaultDrop:
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "connection cannot be dropped", http.StatusInternalServerError)
+			return
+		}
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			conn.Close()
+		}
+	case FaultCorrupt:
+		p.proxy.ServeHTTP(w, r.WithContext(withCorruption(r.Context())))
+	}
+}
+
+// takeFault returns the first fault matching the request and counts the hit, dropping used-up faults
+func (p *faultProxy) takeFault(method, path string) *Fault {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, fault := range p.faults {
+		if !fault.matches(method, path) {
+			continue
+		}
+		fault.hits++
+		if fault.Times > 0 && fault.hits >= fault.Times {
+			p.faults = append(p.faults[:i], p.faults[i+1:]...)
+		}
+		return fault
+	}
+	return nil
+}
+
+// modifyResponse corrupts the body of responses to requests marked for corruption
+func (p *faultProxy) modifyResponse(resp *http.Response) error {
+	if !corruptionRequested(resp.Request.Context()) {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	corrupted := append(body[:len(body)/2:len(body)/2], []byte("\x00#corrupted")...)
+	resp.Body = io.NopCloser(bytes.NewReader(corrupted))
+	resp.ContentLength = int64(len(corrupted))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(corrupted)))
+	return nil
+}
+
+// matches reports whether the fault applies to a request
+func (f *Fault) matches(method, path string) bool {
+	if f.method != "" && !strings.EqualFold(f.method, method) {
+		return false
+	}
+	if strings.HasSuffix(f.path, "*") {
+		return strings.HasPrefix(path, strings.TrimSuffix(f.path, "*"))
+	}
+	return path == f.path
+}
+
+// parseRoute splits "POST /product" into its method and path; the method is optional
+func parseRoute(route string) (string, string, error) {
+	fields := strings.Fields(route)
+	switch {
+	case len(fields) == 1 && strings.HasPrefix(fields[0], "/"):
+		return "", fields[0], nil
+	case len(fields) == 2 && strings.HasPrefix(fields[1], "/"):
+		return strings.ToUpper(fields[0]), fields[1], nil
+	}
+	return "", "", fmt.Errorf("invalid route %q, expected a path such as \"/product\" or \"POST /product\"", route)
+}
+
+// corruptionKey marks proxied requests whose response body should be corrupted
+type corruptionKey struct{}
+
+func withCorruption(ctx context.Context) context.Context {
+	return context.WithValue(ctx, corruptionKey{}, true)
+}
+
+func corruptionRequested(ctx context.Context) bool {
+	corrupt, _ := ctx.Value(corruptionKey{}).(bool)
+	return corrupt
+}
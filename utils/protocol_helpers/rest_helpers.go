@@ -4,37 +4,91 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"test-in-go/config"
+	responsehelpers "test-in-go/utils/response_helpers"
+	"time"
 )
 
 func PostRequest(endpoint string, payload interface{}) (*http.Response, error) {
+	return SendRequest("POST", endpoint, payload)
+}
+
+// SendRequest sends a request with an optional JSON payload to an endpoint of the configured API.
+// Depending on the cassette mode the request is sent live, recorded or answered from the scenario's cassette.
+func SendRequest(method, endpoint string, payload interface{}) (*http.Response, error) {
 	// Convert payload to JSON
-	payloadJSON, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %v", err)
+	var body []byte
+	headers := map[string]string{}
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %v", err)
+		}
+		body = payloadJSON
+		headers["Content-Type"] = "application/json"
+		headers["charset"] = "utf-8"
+	}
+	return SendRawRequest(method, endpoint, body, headers)
+}
+
+// SendRawRequest sends a request with a body that is sent as it is, e.g. from a doc string, and extra headers.
+// The headers are set after Basic Authentication, so an Authorization header replaces it.
+func SendRawRequest(method, endpoint string, body []byte, headers map[string]string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
 	}
 
 	// Create the API request
-	apiURL := config.GetEnv("API_URL") + endpoint
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(payloadJSON))
+	apiURL := apiBaseURL() + endpoint
+	req, err := http.NewRequest(method, apiURL, reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create API request: %v", err)
 	}
 
 	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("charset", "utf-8")
 	username := config.GetEnv("API_USER")
 	password := config.GetEnv("API_PASS")
 	req.SetBasicAuth(username, password) // Set Basic Authentication
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 
 	// Execute the API request
-	client := &http.Client{}
+	client, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send API request: %v", err)
 	}
 
+	// Keep the response for later steps and ${response...} expressions
+	if err := responsehelpers.StoreResponse(resp); err != nil {
+		return nil, err
+	}
 	return resp, nil
 }
+
+// newHTTPClient creates an HTTP client honouring the configured API timeout, cassette mode and contract
+func newHTTPClient() (*http.Client, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	cassette, err := cassettes()
+	if err != nil {
+		return nil, err
+	}
+	transport, err := withContract(cassette)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout:   time.Duration(cfg.API.Timeout) * time.Second,
+		Transport: transport,
+	}, nil
+}
@@ -3,6 +3,7 @@ package report_helpers
 import (
 	"fmt"
 	"os"
+	"test-in-go/config"
 	"test-in-go/utils/data_helpers"
 )
 
@@ -15,12 +16,20 @@ func InitPrettyReport() error {
 	if err != nil {
 		return fmt.Errorf("failed to create report file: %v", err)
 	}
+
+	// The seed reproduces the random test data of this run with --seed, the clock its dates, and the round its IDs
+	header := fmt.Sprintf("RANDOM SEED: %d (rerun with --seed %d)\nCLOCK: %s\nTEST ROUND: %d (testDayN %d)\n",
+		data_helpers.Seed(), data_helpers.Seed(), data_helpers.ClockDescription(), data_helpers.TestRound, data_helpers.TestVariables["testDayN"])
+	fmt.Print(header)
+	if _, err := reportFile.WriteString(header); err != nil {
+		return fmt.Errorf("failed to write report header: %v", err)
+	}
 	return nil
 }
 
 // PrettyLogStep logs step results in a human-readable format
 func PrettyLogStep(stepName, status, details string) error {
-	log := fmt.Sprintf("STEP: %s | STATUS: %s | DETAILS: %s\n", stepName, status, details)
+	log := config.MaskSecrets(fmt.Sprintf("STEP: %s | STATUS: %s | DETAILS: %s\n", stepName, status, details))
 	fmt.Print(log)
 	_, err := reportFile.WriteString(log)
 	if err != nil {
@@ -31,7 +40,7 @@ func PrettyLogStep(stepName, status, details string) error {
 
 // PrettyLogScenario logs scenario results in a human-readable format
 func PrettyLogScenario(scenarioName, status string) error {
-	log := fmt.Sprintf("SCENARIO: %s | STATUS: %s\n", scenarioName, status)
+	log := config.MaskSecrets(fmt.Sprintf("SCENARIO: %s | STATUS: %s\n", scenarioName, status))
 	fmt.Print(log)
 	_, err := reportFile.WriteString(log)
 	if err != nil {
@@ -0,0 +1,191 @@
+package report_helpers
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// maxStepExamples is the number of feature lines shown as examples of a step definition
+const maxStepExamples = 3
+
+// StepCatalogue lists the step definitions of the framework, where the features use them,
+// and the feature steps that no definition matches
+type StepCatalogue struct {
+	Definitions []CataloguedStep
+	Undefined   []StepUse
+}
+
+// CataloguedStep is a step definition and the feature steps it matches
+type CataloguedStep struct {
+	Pattern  string
+	Function string
+	File     string
+	Line     int
+	Uses     []StepUse
+}
+
+// StepUse is a step of a feature file
+type StepUse struct {
+	Text string
+	File string
+	Line int
+}
+
+// Unused returns the step definitions that no feature uses
+func (c *StepCatalogue) Unused() []CataloguedStep {
+	var unused []CataloguedStep
+	for _, step := range c.Definitions {
+		if len(step.Uses) == 0 {
+			unused = append(unused, step)
+		}
+	}
+	return unused
+}
+
+// Examples returns the first uses of a step, to show how it is written
+func (s CataloguedStep) Examples() []StepUse {
+	if len(s.Uses) > maxStepExamples {
+		return s.Uses[:maxStepExamples]
+	}
+	return s.Uses
+}
+
+// location returns the file and line of a step definition or use, e.g. "steps/inbound/product_steps.go:42"
+func location(file string, line int) string {
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// WriteStepCatalogue writes the catalogue as "text" for the console, "markdown" or "html"
+func WriteStepCatalogue(w io.Writer, catalogue *StepCatalogue, format string) error {
+	switch format {
+	case "text":
+		return writeStepCatalogueText(w, catalogue)
+	case "markdown", "md":
+		return writeStepCatalogueMarkdown(w, catalogue)
+	case "html":
+		return stepCatalogueHTML.Execute(w, catalogue)
+	}
+	return fmt.Errorf("unknown step catalogue format %q (expected text, markdown or html)", format)
+}
+
+// writeStepCatalogueText writes the catalogue in the layout of the pretty report
+func writeStepCatalogueText(w io.Writer, catalogue *StepCatalogue) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "STEP DEFINITIONS (%d, %d unused)\n", len(catalogue.Definitions), len(catalogue.Unused()))
+	for _, step := range catalogue.Definitions {
+		fmt.Fprintf(&b, "\n%s\n", step.Pattern)
+		fmt.Fprintf(&b, "    %s (%s), %d use(s)\n", step.Function, location(step.File, step.Line), len(step.Uses))
+		for _, use := range step.Examples() {
+			fmt.Fprintf(&b, "    e.g. %s (%s)\n", use.Text, location(use.File, use.Line))
+		}
+	}
+
+	fmt.Fprintf(&b, "\nUNUSED STEPS (%d)\n", len(catalogue.Unused()))
+	for _, step := range catalogue.Unused() {
+		fmt.Fprintf(&b, "    %s (%s)\n", step.Pattern, location(step.File, step.Line))
+	}
+
+	fmt.Fprintf(&b, "\nUNDEFINED STEPS (%d)\n", len(catalogue.Undefined))
+	for _, use := range catalogue.Undefined {
+		fmt.Fprintf(&b, "    %s (%s)\n", use.Text, location(use.File, use.Line))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeStepCatalogueMarkdown writes the catalogue as Markdown tables
+func writeStepCatalogueMarkdown(w io.Writer, catalogue *StepCatalogue) error {
+	var b strings.Builder
+	b.WriteString("# Step Catalogue\n\n")
+	fmt.Fprintf(&b, "%d step definitions, %d unused, %d undefined steps in features.\n\n", len(catalogue.Definitions), len(catalogue.Unused()), len(catalogue.Undefined))
+
+	b.WriteString("## Step Definitions\n\n")
+	b.WriteString("| Step | Function | Uses | Examples |\n")
+	b.WriteString("|------|----------|------|----------|\n")
+	for _, step := range catalogue.Definitions {
+		var examples []string
+		for _, use := range step.Examples() {
+			examples = append(examples, fmt.Sprintf("`%s` (%s)", markdownCell(use.Text), location(use.File, use.Line)))
+		}
+		fmt.Fprintf(&b, "| `%s` | `%s` (%s) | %d | %s |\n", markdownCell(step.Pattern), step.Function, location(step.File, step.Line), len(step.Uses), strings.Join(examples, "<br>"))
+	}
+
+	b.WriteString("\n## Unused Steps\n\n")
+	if len(catalogue.Unused()) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, step := range catalogue.Unused() {
+		fmt.Fprintf(&b, "- `%s` (%s)\n", markdownCode(step.Pattern), location(step.File, step.Line))
+	}
+
+	b.WriteString("\n## Undefined Steps\n\n")
+	if len(catalogue.Undefined) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, use := range catalogue.Undefined {
+		fmt.Fprintf(&b, "- `%s` (%s)\n", markdownCode(use.Text), location(use.File, use.Line))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes the characters that would end a table cell or a code span
+func markdownCell(text string) string {
+	return strings.ReplaceAll(markdownCode(text), "|", `\|`)
+}
+
+// markdownCode replaces the backquotes that would end a code span
+func markdownCode(text string) string {
+	return strings.ReplaceAll(text, "`", "'")
+}
+
+// stepCatalogueHTML is a self-contained page for readers without the repository
+var stepCatalogueHTML = template.Must(template.New("steps").Funcs(template.FuncMap{"location": location}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Step Catalogue</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+code { font-size: 0.9em; }
+.unused { background: #fff4e0; }
+.location { color: #666; }
+</style>
+</head>
+<body>
+<h1>Step Catalogue</h1>
+<p>{{len .Definitions}} step definitions, {{len .Unused}} unused, {{len .Undefined}} undefined steps in features.</p>
+
+<h2>Step Definitions</h2>
+<table>
+<tr><th>Step</th><th>Function</th><th>Uses</th><th>Examples</th></tr>
+{{- range .Definitions}}
+<tr{{if not .Uses}} class="unused"{{end}}>
+<td><code>{{.Pattern}}</code></td>
+<td><code>{{.Function}}</code><br><span class="location">{{location .File .Line}}</span></td>
+<td>{{len .Uses}}</td>
+<td>{{range .Examples}}<code>{{.Text}}</code> <span class="location">{{location .File .Line}}</span><br>{{end}}</td>
+</tr>
+{{- end}}
+</table>
+
+<h2>Unused Steps</h2>
+{{if .Unused}}<ul>
+{{- range .Unused}}
+<li><code>{{.Pattern}}</code> <span class="location">{{location .File .Line}}</span></li>
+{{- end}}
+</ul>{{else}}<p>None.</p>{{end}}
+
+<h2>Undefined Steps</h2>
+{{if .Undefined}}<ul>
+{{- range .Undefined}}
+<li><code>{{.Text}}</code> <span class="location">{{location .File .Line}}</span></li>
+{{- end}}
+</ul>{{else}}<p>None.</p>{{end}}
+</body>
+</html>
+`))
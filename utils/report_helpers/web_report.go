@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"test-in-go/config"
 )
 
 // ServeReport serves the pretty report via a web UI.
@@ -13,7 +14,7 @@ func ServeReport() {
 	http.ListenAndServe(":8080", nil)
 }
 
-// reportHandler reads and displays the report content.
+// reportHandler reads and displays the report content with secrets masked.
 func reportHandler(w http.ResponseWriter, r *http.Request) {
 	data, err := ioutil.ReadFile("./reports/pretty-report.txt")
 	if err != nil {
@@ -21,5 +22,5 @@ func reportHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set("Content-Type", "text/plain")
-	w.Write(data)
+	w.Write([]byte(config.MaskSecrets(string(data))))
 }
@@ -0,0 +1,114 @@
+package responsehelpers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	validationhelpers "test-in-go/utils/validation_helpers"
+)
+
+// StoredResponse is an API response kept for later steps and ${response...} expressions
+type StoredResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// The last response of the scenario and the responses saved under a name
+var (
+	storeMutex     sync.Mutex
+	lastResponse   *StoredResponse
+	namedResponses = make(map[string]*StoredResponse)
+)
+
+// StoreResponse keeps a copy of a response as the last response of the scenario.
+// The body is read and replaced with a re-readable copy, so the caller can still read it.
+func StoreResponse(resp *http.Response) error {
+	var body []byte
+	if resp.Body != nil {
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read response body: %v", err)
+		}
+		body = data
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	lastResponse = &StoredResponse{Status: resp.StatusCode, Header: resp.Header.Clone(), Body: body}
+	return nil
+}
+
+// LastResponse returns the last response of the scenario
+func LastResponse() (*StoredResponse, error) {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	if lastResponse == nil {
+		return nil, fmt.Errorf("no API response has been received in this scenario")
+	}
+	return lastResponse, nil
+}
+
+// SaveResponse keeps the last response under a name, e.g. to compare it with a later one
+func SaveResponse(name string) error {
+	response, err := LastResponse()
+	if err != nil {
+		return err
+	}
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	namedResponses[name] = response
+	return nil
+}
+
+// NamedResponse returns a response saved with SaveResponse
+func NamedResponse(name string) (*StoredResponse, error) {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	response, ok := namedResponses[name]
+	if !ok {
+		return nil, fmt.Errorf("no response was saved as %q", name)
+	}
+	return response, nil
+}
+
+// ClearResponses forgets every response, at the start of a scenario
+func ClearResponses() {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	lastResponse = nil
+	namedResponses = make(map[string]*StoredResponse)
+}
+
+// Value returns a part of the response as text: "status", "body", "header.<Name>" or a JSONPath such as "$.id"
+func (r *StoredResponse) Value(selector string) (string, error) {
+	switch {
+	case selector == "status":
+		return strconv.Itoa(r.Status), nil
+	case selector == "body":
+		return string(r.Body), nil
+	case strings.HasPrefix(selector, "header."):
+		name := strings.TrimPrefix(selector, "header.")
+		if values := r.Header.Values(name); len(values) > 0 {
+			return values[0], nil
+		}
+		return "", fmt.Errorf("response has no %s header", name)
+	case strings.HasPrefix(selector, "$"):
+		document, err := validationhelpers.ParseJSON(r.Body)
+		if err != nil {
+			return "", fmt.Errorf("response body: %v", err)
+		}
+		value, err := validationhelpers.GetJSONPathValue(document, selector)
+		if err != nil {
+			return "", err
+		}
+		return validationhelpers.FormatJSONValue(value), nil
+	}
+	return "", fmt.Errorf("unknown response selector %q, expected status, body, header.<Name> or a JSONPath", selector)
+}
@@ -1 +1,89 @@
 package validationhelpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrJSONPathNotFound is wrapped by GetJSONPathValue when a field of the path does not exist
+var ErrJSONPathNotFound = errors.New("not found")
+
+// jsonPathSegment matches one segment of a JSONPath after the root: .name, ['name'] or [index]
+var jsonPathSegment = regexp.MustCompile(`^(?:\.([^.\[\]]+)|\['([^']*)'\]|\[(\d+)\])`)
+
+// ParseJSON decodes a JSON document into generic maps, slices and values.
+// Numbers are decoded as float64, as with encoding/json defaults.
+func ParseJSON(data []byte) (interface{}, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return document, nil
+}
+
+// GetJSONPathValue returns the value at a simple JSONPath such as $.products[0].productCode or $['id'].
+func GetJSONPathValue(document interface{}, path string) (interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath %q must start with $", path)
+	}
+
+	current := document
+	rest := path[1:]
+	for rest != "" {
+		match := jsonPathSegment.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf("unsupported JSONPath syntax at %q in %q", rest, path)
+		}
+		rest = rest[len(match[0]):]
+
+		if match[3] != "" {
+			index, _ := strconv.Atoi(match[3])
+			array, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("JSONPath %q: expected an array before [%d]", path, index)
+			}
+			if index >= len(array) {
+				return nil, fmt.Errorf("JSONPath %q: index %d out of range (length %d)", path, index, len(array))
+			}
+			current = array[index]
+			continue
+		}
+
+		key := match[1] + match[2]
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("JSONPath %q: expected an object before %q", path, key)
+		}
+		value, exists := object[key]
+		if !exists {
+			return nil, fmt.Errorf("JSONPath %q: field %q %w", path, key, ErrJSONPathNotFound)
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// FormatJSONValue renders a decoded JSON value as text: strings as is, whole numbers without decimals,
+// objects and arrays as compact JSON.
+func FormatJSONValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
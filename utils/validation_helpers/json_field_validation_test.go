@@ -0,0 +1,50 @@
+package validationhelpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetJSONPathValue(t *testing.T) {
+	document, err := ParseJSON([]byte(`{"productCode": "PRD-1", "sku": [{"skuId": "S1", "barcode": null}], "price": 2.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		want         interface{}
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "field", path: "$.productCode", want: "PRD-1"},
+		{name: "bracket field", path: "$['productCode']", want: "PRD-1"},
+		{name: "array element field", path: "$.sku[0].skuId", want: "S1"},
+		{name: "null field", path: "$.sku[0].barcode", want: nil},
+		{name: "number", path: "$.price", want: 2.5},
+		{name: "missing field", path: "$.description", wantErr: true, wantNotFound: true},
+		{name: "missing nested field", path: "$.sku[0].uom", wantErr: true, wantNotFound: true},
+		{name: "index out of range", path: "$.sku[1].skuId", wantErr: true},
+		{name: "index on an object", path: "$.productCode[0]", wantErr: true},
+		{name: "field on a scalar", path: "$.price.cents", wantErr: true},
+		{name: "no root", path: "productCode", wantErr: true},
+		{name: "unsupported syntax", path: "$..skuId", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetJSONPathValue(document, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetJSONPathValue(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			// Only fields missing from the document are ErrJSONPathNotFound; stub db writes store them as NULL
+			if errors.Is(err, ErrJSONPathNotFound) != tt.wantNotFound {
+				t.Errorf("GetJSONPathValue(%q) error = %v, want ErrJSONPathNotFound %v", tt.path, err, tt.wantNotFound)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("GetJSONPathValue(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -1 +1,284 @@
 package validationhelpers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaResolver looks up the schema a $ref such as "#/components/schemas/Product" points to
+type SchemaResolver func(ref string) (map[string]interface{}, error)
+
+// uuidPattern validates the "uuid" string format
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidateJSONSchema checks a decoded JSON value against a schema and returns every violation, prefixed with its JSONPath.
+// The OpenAPI 3 subset of JSON Schema is supported: type, nullable, enum, properties, required, additionalProperties,
+// items, minItems/maxItems, minLength/maxLength, pattern, format (date, date-time, uuid, email),
+// minimum/maximum, allOf/anyOf/oneOf and $ref through the resolver.
+func ValidateJSONSchema(schema map[string]interface{}, value interface{}, resolver SchemaResolver) []string {
+	return validateSchema(schema, value, "$", resolver, 0)
+}
+
+// validateSchema validates one value; depth guards against recursive $refs
+func validateSchema(schema map[string]interface{}, value interface{}, path string, resolver SchemaResolver, depth int) []string {
+	if depth > 64 {
+		return []string{fmt.Sprintf("%s: schema nesting is too deep", path)}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if resolver == nil {
+			return []string{fmt.Sprintf("%s: cannot resolve %s", path, ref)}
+		}
+		resolved, err := resolver(ref)
+		if err != nil {
+			return []string{fmt.Sprintf("%s: %v", path, err)}
+		}
+		return validateSchema(resolved, value, path, resolver, depth+1)
+	}
+
+	var violations []string
+	violations = append(violations, validateCombinators(schema, value, path, resolver, depth)...)
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || schema["type"] == nil || typeAllows(schema["type"], "null") {
+			return violations
+		}
+		return append(violations, fmt.Sprintf("%s: must not be null", path))
+	}
+
+	if schemaType, ok := schema["type"]; ok && !typeAllows(schemaType, jsonType(value)) {
+		return append(violations, fmt.Sprintf("%s: expected %v, got %s", path, schemaType, jsonType(value)))
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if FormatJSONValue(allowed) == FormatJSONValue(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s: %s is not one of %s", path, FormatJSONValue(value), FormatJSONValue(enum)))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		violations = append(violations, validateObject(schema, v, path, resolver, depth)...)
+	case []interface{}:
+		violations = append(violations, validateArray(schema, v, path, resolver, depth)...)
+	case string:
+		violations = append(violations, validateString(schema, v, path)...)
+	case float64:
+		violations = append(violations, validateNumber(schema, v, path)...)
+	}
+	return violations
+}
+
+// validateCombinators applies allOf, anyOf and oneOf
+func validateCombinators(schema map[string]interface{}, value interface{}, path string, resolver SchemaResolver, depth int) []string {
+	var violations []string
+	for _, sub := range schemaList(schema["allOf"]) {
+		violations = append(violations, validateSchema(sub, value, path, resolver, depth+1)...)
+	}
+	if anyOf := schemaList(schema["anyOf"]); len(anyOf) > 0 {
+		if matchingSchemas(anyOf, value, path, resolver, depth) == 0 {
+			violations = append(violations, fmt.Sprintf("%s: does not match any schema of anyOf", path))
+		}
+	}
+	if oneOf := schemaList(schema["oneOf"]); len(oneOf) > 0 {
+		if n := matchingSchemas(oneOf, value, path, resolver, depth); n != 1 {
+			violations = append(violations, fmt.Sprintf("%s: must match exactly one schema of oneOf, matches %d", path, n))
+		}
+	}
+	return violations
+}
+
+// validateObject checks required and declared properties, and undeclared ones when additionalProperties restricts them
+func validateObject(schema map[string]interface{}, object map[string]interface{}, path string, resolver SchemaResolver, depth int) []string {
+	var violations []string
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, exists := object[fmt.Sprint(name)]; !exists {
+				violations = append(violations, fmt.Sprintf("%s: missing required property %q", path, name))
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "." + name
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			violations = append(violations, validateSchema(property, object[name], propertyPath, resolver, depth+1)...)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				violations = append(violations, fmt.Sprintf("%s: property is not allowed", propertyPath))
+			}
+		case map[string]interface{}:
+			violations = append(violations, validateSchema(additional, object[name], propertyPath, resolver, depth+1)...)
+		}
+	}
+	return violations
+}
+
+// validateArray checks the size and the items of an array
+func validateArray(schema map[string]interface{}, array []interface{}, path string, resolver SchemaResolver, depth int) []string {
+	var violations []string
+	if min, ok := number(schema["minItems"]); ok && float64(len(array)) < min {
+		violations = append(violations, fmt.Sprintf("%s: expected at least %v items, got %d", path, min, len(array)))
+	}
+	if max, ok := number(schema["maxItems"]); ok && float64(len(array)) > max {
+		violations = append(violations, fmt.Sprintf("%s: expected at most %v items, got %d", path, max, len(array)))
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range array {
+			violations = append(violations, validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i), resolver, depth+1)...)
+		}
+	}
+	return violations
+}
+
+// validateString checks length, pattern and format
+func validateString(schema map[string]interface{}, text, path string) []string {
+	var violations []string
+	length := len([]rune(text))
+	if min, ok := number(schema["minLength"]); ok && float64(length) < min {
+		violations = append(violations, fmt.Sprintf("%s: expected at least %v characters, got %d", path, min, length))
+	}
+	if max, ok := number(schema["maxLength"]); ok && float64(length) > max {
+		violations = append(violations, fmt.Sprintf("%s: expected at most %v characters, got %d", path, max, length))
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s: invalid pattern %q in schema: %v", path, pattern, err))
+		} else if !re.MatchString(text) {
+			violations = append(violations, fmt.Sprintf("%s: %q does not match pattern %q", path, text, pattern))
+		}
+	}
+
+	valid := true
+	switch schema["format"] {
+	case "date":
+		_, err := time.Parse("2006-01-02", text)
+		valid = err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, text)
+		valid = err == nil
+	case "uuid":
+		valid = uuidPattern.MatchString(text)
+	case "email":
+		valid = strings.Count(text, "@") == 1 && !strings.HasPrefix(text, "@") && !strings.HasSuffix(text, "@")
+	}
+	if !valid {
+		violations = append(violations, fmt.Sprintf("%s: %q is not a valid %v", path, text, schema["format"]))
+	}
+	return violations
+}
+
+// validateNumber checks integer types and bounds
+func validateNumber(schema map[string]interface{}, n float64, path string) []string {
+	var violations []string
+	if min, ok := number(schema["minimum"]); ok && n < min {
+		violations = append(violations, fmt.Sprintf("%s: %v is less than the minimum %v", path, n, min))
+	}
+	if max, ok := number(schema["maximum"]); ok && n > max {
+		violations = append(violations, fmt.Sprintf("%s: %v is greater than the maximum %v", path, n, max))
+	}
+	return violations
+}
+
+// matchingSchemas counts the schemas a value is valid against
+func matchingSchemas(schemas []map[string]interface{}, value interface{}, path string, resolver SchemaResolver, depth int) int {
+	matches := 0
+	for _, sub := range schemas {
+		if len(validateSchema(sub, value, path, resolver, depth+1)) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// typeAllows reports whether a schema type (a name or a list of names) accepts a JSON type
+func typeAllows(schemaType interface{}, actual string) bool {
+	var allowed []string
+	switch t := schemaType.(type) {
+	case string:
+		allowed = []string{t}
+	case []interface{}:
+		for _, name := range t {
+			allowed = append(allowed, fmt.Sprint(name))
+		}
+	default:
+		return true
+	}
+	for _, name := range allowed {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of a decoded value, telling integers from other numbers
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case int, int64:
+		return "integer"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaList converts an allOf/anyOf/oneOf list into schemas
+func schemaList(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	var schemas []map[string]interface{}
+	for _, item := range list {
+		if schema, ok := item.(map[string]interface{}); ok {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+// number reads a numeric schema keyword, which YAML decodes as int and JSON as float64
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
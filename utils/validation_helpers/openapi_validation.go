@@ -0,0 +1,254 @@
+package validationhelpers
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPISpec is a loaded OpenAPI 3 document used to validate requests and responses
+type OpenAPISpec struct {
+	Path     string
+	document map[string]interface{}
+	basePath string
+	paths    []string
+}
+
+// Operation is one method of one path of the specification
+type Operation struct {
+	Method     string
+	Path       string
+	definition map[string]interface{}
+}
+
+// LoadOpenAPISpec reads an OpenAPI 3 document in YAML or JSON.
+// The path of the first server URL, if any, is the base path requests are matched below.
+func LoadOpenAPISpec(path string) (*OpenAPISpec, error) {
+	document, err := LoadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	if version, _ := document["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("%s is not an OpenAPI 3 document", path)
+	}
+
+	spec := &OpenAPISpec{Path: path, document: document}
+	paths, _ := document["paths"].(map[string]interface{})
+	for template := range paths {
+		spec.paths = append(spec.paths, template)
+	}
+	// Literal paths are tried before templated ones, e.g. /product/search before /product/{id}
+	sort.Slice(spec.paths, func(i, j int) bool {
+		ti, tj := strings.Count(spec.paths[i], "{"), strings.Count(spec.paths[j], "{")
+		if ti != tj {
+			return ti < tj
+		}
+		return spec.paths[i] < spec.paths[j]
+	})
+
+	if servers, ok := document["servers"].([]interface{}); ok && len(servers) > 0 {
+		if server, ok := servers[0].(map[string]interface{}); ok {
+			if serverURL, err := url.Parse(fmt.Sprint(server["url"])); err == nil {
+				spec.basePath = strings.TrimSuffix(serverURL.Path, "/")
+			}
+		}
+	}
+	return spec, nil
+}
+
+// LoadDocument reads a JSON or YAML document, such as an OpenAPI document or a JSON Schema, into generic maps
+func LoadDocument(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid document %s: %v", path, err)
+	}
+	document, ok := stringKeys(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s does not contain an object", path)
+	}
+	return document, nil
+}
+
+// FindOperation returns the operation for a method and a request path, which may include the server base path
+func (s *OpenAPISpec) FindOperation(method, requestPath string) (*Operation, error) {
+	relative := strings.TrimPrefix(requestPath, s.basePath)
+	if relative == "" {
+		relative = "/"
+	}
+	paths, _ := s.document["paths"].(map[string]interface{})
+	for _, template := range s.paths {
+		if !pathMatchesTemplate(template, relative) {
+			continue
+		}
+		item, _ := paths[template].(map[string]interface{})
+		definition, ok := item[strings.ToLower(method)].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("method %s is not defined for %s", method, template)
+		}
+		return &Operation{Method: strings.ToUpper(method), Path: template, definition: definition}, nil
+	}
+	return nil, fmt.Errorf("path %s is not defined", relative)
+}
+
+// ValidateRequest checks the content type and body of a request against its operation
+func (s *OpenAPISpec) ValidateRequest(operation *Operation, contentType string, body []byte) []string {
+	requestBody, ok := operation.definition["requestBody"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	requestBody = s.resolveObject(requestBody)
+	if len(strings.TrimSpace(string(body))) == 0 {
+		if required, _ := requestBody["required"].(bool); required {
+			return []string{"request body is required"}
+		}
+		return nil
+	}
+	return s.validateContent(requestBody, contentType, body, "request")
+}
+
+// ValidateResponse checks that the status is documented and the content type and body match its definition
+func (s *OpenAPISpec) ValidateResponse(operation *Operation, status int, contentType string, body []byte) []string {
+	responses, _ := operation.definition["responses"].(map[string]interface{})
+	response, ok := responses[strconv.Itoa(status)].(map[string]interface{})
+	if !ok {
+		response, ok = responses[fmt.Sprintf("%dXX", status/100)].(map[string]interface{})
+	}
+	if !ok {
+		response, ok = responses["default"].(map[string]interface{})
+	}
+	if !ok {
+		documented := make([]string, 0, len(responses))
+		for code := range responses {
+			documented = append(documented, code)
+		}
+		sort.Strings(documented)
+		return []string{fmt.Sprintf("response status %d is not documented (documented: %s)", status, strings.Join(documented, ", "))}
+	}
+
+	response = s.resolveObject(response)
+	if _, hasContent := response["content"]; !hasContent || len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+	return s.validateContent(response, contentType, body, "response")
+}
+
+// validateContent finds the media type of a request body or response and validates the body against its schema
+func (s *OpenAPISpec) validateContent(definition map[string]interface{}, contentType string, body []byte, kind string) []string {
+	content, _ := definition["content"].(map[string]interface{})
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []string{fmt.Sprintf("%s has an invalid content type %q", kind, contentType)}
+	}
+	media, ok := content[mediaType].(map[string]interface{})
+	if !ok {
+		declared := make([]string, 0, len(content))
+		for name := range content {
+			declared = append(declared, name)
+		}
+		sort.Strings(declared)
+		return []string{fmt.Sprintf("%s content type %q is not one of %s", kind, mediaType, strings.Join(declared, ", "))}
+	}
+
+	schema, ok := media["schema"].(map[string]interface{})
+	if !ok || !strings.Contains(mediaType, "json") {
+		return nil
+	}
+	document, err := ParseJSON(body)
+	if err != nil {
+		return []string{fmt.Sprintf("%s body: %v", kind, err)}
+	}
+	var violations []string
+	for _, violation := range ValidateJSONSchema(schema, document, s.Resolve) {
+		violations = append(violations, kind+" body "+violation)
+	}
+	return violations
+}
+
+// Resolve returns the object a local $ref such as "#/components/schemas/Product" points to
+func (s *OpenAPISpec) Resolve(ref string) (map[string]interface{}, error) {
+	return ResolveReference(s.document, ref)
+}
+
+// ResolveReference returns the object a local $ref points to within a document
+func ResolveReference(document map[string]interface{}, ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("only local references are supported, got %s", ref)
+	}
+	var current interface{} = document
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference %s", ref)
+		}
+		if current, ok = object[part]; !ok {
+			return nil, fmt.Errorf("unresolvable reference %s", ref)
+		}
+	}
+	resolved, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("reference %s does not point to an object", ref)
+	}
+	return resolved, nil
+}
+
+// resolveObject follows a $ref on a request body or response object, if it has one
+func (s *OpenAPISpec) resolveObject(object map[string]interface{}) map[string]interface{} {
+	if ref, ok := object["$ref"].(string); ok {
+		if resolved, err := s.Resolve(ref); err == nil {
+			return resolved
+		}
+	}
+	return object
+}
+
+// pathMatchesTemplate compares a request path with a path template such as /product/{productCode}
+func pathMatchesTemplate(template, path string) bool {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(templateSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stringKeys converts YAML mappings with non-string keys, such as unquoted status codes, to string-keyed maps
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return converted
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = stringKeys(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+		return v
+	}
+	return value
+}
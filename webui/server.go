@@ -2,20 +2,26 @@ package webui
 
 import (
 	"fmt"
+	"mime"
 	"net/http"
-
-	// "os"
+	"os"
+	"path/filepath"
+	"strings"
+	"test-in-go/config"
 	"test-in-go/utils/report_helpers"
 
 	"github.com/gin-gonic/gin"
 )
 
+// staticDir holds the files served under /static
+const staticDir = "./webui/static"
+
 // StartWebServer starts the web server for the UI
 func StartWebServer() {
 	router := gin.Default()
 
-	// Serve the static HTML files
-	router.Static("/static", "./webui/static")
+	// Serve the static files; text files such as reports are masked like /report
+	router.GET("/static/*filepath", serveStatic)
 	router.LoadHTMLGlob("webui/templates/*")
 
 	// API for running tests
@@ -36,6 +42,16 @@ func StartWebServer() {
 		}
 	})
 
+	// Serve the latest report with secret values masked
+	router.GET("/report", func(c *gin.Context) {
+		data, err := os.ReadFile("./reports/pretty-report.txt")
+		if err != nil {
+			c.String(http.StatusNotFound, "Error reading report file: %v", err)
+			return
+		}
+		c.String(http.StatusOK, config.MaskSecrets(string(data)))
+	})
+
 	// Serve the index page
 	router.GET("/", func(c *gin.Context) {
 		c.HTML(http.StatusOK, "index.html", nil)
@@ -45,6 +61,23 @@ func StartWebServer() {
 	router.Run(":8080")
 }
 
+// serveStatic serves a file of webui/static, masking secret values in text files
+func serveStatic(c *gin.Context) {
+	path := filepath.Join(staticDir, filepath.Clean("/"+c.Param("filepath")))
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if !strings.HasPrefix(contentType, "text/") && !strings.Contains(contentType, "json") {
+		c.File(path)
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.String(http.StatusNotFound, "Error reading file: %v", err)
+		return
+	}
+	c.Data(http.StatusOK, contentType, []byte(config.MaskSecrets(string(data))))
+}
+
 // runTests is a helper function to run tests programmatically
 func runTests() int {
 	// Here, you can call the main test function or godog to run tests